@@ -0,0 +1,90 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/service"
+	"net/http"
+	"strings"
+)
+
+// @Summary Get products in any state
+// @Description Get products in all lifecycle states, optionally including soft-deleted ones (requires admin)
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param status query string false "Comma-separated statuses: draft, active, archived"
+// @Param deleted query bool false "Include soft-deleted products"
+// @Success 200 {array} domain.Product
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/admin/products [get]
+func getAdminProductsHandler(catalogService *service.CatalogService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var statuses []string
+		if status := c.Query("status"); status != "" {
+			statuses = strings.Split(status, ",")
+		}
+		products, err := catalogService.GetProductsForAdmin(statuses, c.Query("deleted") == "true")
+		if err != nil {
+			if errors.Is(err, domain.ErrInvalidProduct) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, products)
+	}
+}
+
+// @Summary Get product in any state
+// @Description Get a product by its UUID regardless of its lifecycle state (requires admin)
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Success 200 {object} domain.Product
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Product not found"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/admin/products/{id} [get]
+func getAdminProductHandler(catalogService *service.CatalogService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		product, err := catalogService.GetProductByIDForAdmin(c.Param("id"))
+		if err != nil {
+			productError(c, err)
+			return
+		}
+		c.Header("ETag", productETag(product))
+		c.JSON(http.StatusOK, product)
+	}
+}
+
+// @Summary Restore a deleted product
+// @Description Restore a soft-deleted product (requires admin)
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Success 200 {object} domain.Product
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Deleted product not found"
+// @Router /api/v1/admin/products/{id}/restore [post]
+func restoreProductHandler(catalogService *service.CatalogService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		product, err := catalogService.RestoreProduct(c.Param("id"), actorFrom(c))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "deleted product not found"})
+			return
+		}
+		c.Header("ETag", productETag(product))
+		c.JSON(http.StatusOK, product)
+	}
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/yangirxd/store-app/catalog/api/dto"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/service"
+	"net/http"
+	"strings"
+)
+
+// attributeFilter собирает параметры вида attr[brand]=acme,globex и проверяет их по определениям характеристик
+func attributeFilter(c *gin.Context, attributeService *service.AttributeService) (map[string][]string, bool) {
+	raw := make(map[string][]string)
+	for key, values := range c.Request.URL.Query() {
+		if !strings.HasPrefix(key, "attr[") || !strings.HasSuffix(key, "]") {
+			continue
+		}
+		code := strings.ToLower(key[len("attr[") : len(key)-1])
+		for _, value := range values {
+			for _, part := range strings.Split(value, ",") {
+				if part = strings.TrimSpace(part); part != "" {
+					raw[code] = append(raw[code], part)
+				}
+			}
+		}
+	}
+
+	attributes, err := attributeService.ParseFilter(raw)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidAttribute) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return attributes, true
+}
+
+// @Summary Get attribute definitions
+// @Description Get all product attribute definitions
+// @Tags attributes
+// @Produce json
+// @Success 200 {array} domain.AttributeDefinition
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/attributes [get]
+func getAttributesHandler(attributeService *service.AttributeService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		definitions, err := attributeService.GetAttributes()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, definitions)
+	}
+}
+
+// @Summary Create an attribute definition
+// @Description Define a typed product attribute (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param input body dto.CreateAttributeRequest true "Attribute definition"
+// @Success 201 {object} domain.AttributeDefinition
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 409 {string} string "Attribute already exists"
+// @Router /api/v1/admin/attributes [post]
+func createAttributeHandler(attributeService *service.AttributeService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.CreateAttributeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filterable := req.Filterable == nil || *req.Filterable
+		definition, err := attributeService.CreateAttribute(req.Code, req.Name, req.Type, req.Options, filterable)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrInvalidAttribute):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, domain.ErrAttributeExists):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			}
+			return
+		}
+		c.JSON(http.StatusCreated, definition)
+	}
+}
+
+// @Summary Delete an attribute definition
+// @Description Delete an attribute definition and its values on all products (admin only)
+// @Tags admin
+// @Param Authorization header string true "Bearer token"
+// @Param code path string true "Attribute code"
+// @Success 204 {string} string "No Content"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Attribute not found"
+// @Router /api/v1/admin/attributes/{code} [delete]
+func deleteAttributeHandler(attributeService *service.AttributeService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := attributeService.DeleteAttribute(c.Param("code")); err != nil {
+			if errors.Is(err, domain.ErrAttributeNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNoContent, gin.H{})
+	}
+}
+
+// @Summary Set product attributes
+// @Description Replace all attribute values of a product (requires authentication)
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param If-Match header string true "ETag of the product being updated"
+// @Param id path string true "Product ID"
+// @Param input body dto.SetProductAttributesRequest true "Attribute values by code"
+// @Success 200 {object} domain.Product
+// @Header 200 {string} ETag "New product version"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Product belongs to another seller"
+// @Failure 404 {string} string "Product not found"
+// @Failure 412 {string} string "Precondition Failed"
+// @Failure 428 {string} string "Precondition Required"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/products/{id}/attributes [put]
+func setProductAttributesHandler(attributeService *service.AttributeService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		version, ok := requireIfMatch(c)
+		if !ok {
+			return
+		}
+		var req dto.SetProductAttributesRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		product, err := attributeService.SetProductAttributes(c.Param("id"), version, actorFrom(c), req.Attributes)
+		if err != nil {
+			productError(c, err)
+			return
+		}
+		c.Header("ETag", productETag(product))
+		c.JSON(http.StatusOK, product)
+	}
+}
+
+// @Summary Get product facets
+// @Description Count public products by values of filterable attributes; accepts the same attr filters as the product list
+// @Tags products
+// @Produce json
+// @Param attr[code] query string false "Attribute filter, e.g. attr[brand]=acme; comma-separated values are alternatives"
+// @Success 200 {array} domain.AttributeFacet
+// @Failure 400 {string} string "Bad Request"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/products/facets [get]
+func getProductFacetsHandler(attributeService *service.AttributeService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		attributes, ok := attributeFilter(c, attributeService)
+		if !ok {
+			return
+		}
+		facets, err := attributeService.GetFacets(attributes)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, facets)
+	}
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yangirxd/store-app/catalog/service"
+	"net/http"
+	"strconv"
+)
+
+// @Summary Get catalog audit trail
+// @Description Get recorded product changes with field-level diffs, newest first (requires admin)
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param productId query string false "Product ID"
+// @Param actor query string false "Email of the user who made the change, or system"
+// @Param action query string false "created, updated, deleted or restored"
+// @Param limit query int false "Maximum number of entries (default 100)"
+// @Success 200 {array} domain.AuditRecord
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Router /api/v1/admin/audit [get]
+func getAuditRecordsHandler(auditService *service.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writeAuditRecords(c, auditService, c.Query("productId"))
+	}
+}
+
+// @Summary Get product audit trail
+// @Description Get recorded changes of a product with field-level diffs, newest first (requires admin)
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param actor query string false "Email of the user who made the change, or system"
+// @Param action query string false "created, updated, deleted or restored"
+// @Param limit query int false "Maximum number of entries (default 100)"
+// @Success 200 {array} domain.AuditRecord
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Router /api/v1/admin/products/{id}/audit [get]
+func getProductAuditRecordsHandler(auditService *service.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writeAuditRecords(c, auditService, c.Param("id"))
+	}
+}
+
+func writeAuditRecords(c *gin.Context, auditService *service.AuditService, productID string) {
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+	records, err := auditService.GetAuditRecords(productID, c.Query("actor"), c.Query("action"), limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, records)
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yangirxd/store-app/catalog/api/dto"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/service"
+	"net/http"
+)
+
+// @Summary Set bundle components
+// @Description Replace the components of a bundle. A product with components becomes a bundle: its stock is the number of complete sets the component stock allows, and reservations and orders of the bundle take stock from the components. An empty list turns the bundle back into a regular product (requires authentication)
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param If-Match header string true "ETag of the product being updated"
+// @Param id path string true "Product ID"
+// @Param input body dto.SetBundleComponentsRequest true "Bundle components"
+// @Success 200 {object} domain.Product
+// @Header 200 {string} ETag "New product version"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Product belongs to another seller"
+// @Failure 404 {string} string "Product not found"
+// @Failure 412 {string} string "Precondition Failed"
+// @Failure 428 {string} string "Precondition Required"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/products/{id}/components [put]
+func setBundleComponentsHandler(catalogService *service.CatalogService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		version, ok := requireIfMatch(c)
+		if !ok {
+			return
+		}
+		var req dto.SetBundleComponentsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		components := make([]domain.BundleComponent, 0, len(req.Components))
+		for _, component := range req.Components {
+			components = append(components, domain.BundleComponent{ProductID: component.ProductID, Quantity: component.Quantity})
+		}
+		product, err := catalogService.SetBundleComponents(c.Param("id"), version, actorFrom(c), components)
+		if err != nil {
+			productError(c, err)
+			return
+		}
+		c.Header("ETag", productETag(product))
+		c.JSON(http.StatusOK, product)
+	}
+}
@@ -1,15 +1,162 @@
 package dto
 
+import (
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"time"
+)
+
 type CreateProductRequest struct {
+	SKU         string  `json:"sku"`
 	Name        string  `json:"name" binding:"required"`
 	Description string  `json:"description"`
-	Price       float64 `json:"price" binding:"required,gt=0"`
-	Stock       int     `json:"stock" binding:"required,gte=0"`
+	Price       float64 `json:"price" binding:"gte=0"`
+	Stock       int     `json:"stock" binding:"gte=0"`
+	Status      string  `json:"status" binding:"omitempty,oneof=draft active archived"`
+	TaxClass    string  `json:"taxClass" binding:"omitempty,oneof=standard reduced exempt"`
 }
 
 type UpdateProductRequest struct {
 	Name        string  `json:"name"`
 	Description string  `json:"description"`
-	Price       float64 `json:"price" binding:"gt=0"`
+	Price       float64 `json:"price" binding:"gte=0"`
 	Stock       int     `json:"stock" binding:"gte=0"`
 }
+
+type BatchGetProductsRequest struct {
+	IDs []string `json:"ids" binding:"required,min=1,max=100"`
+}
+
+type BatchGetProductsResponse struct {
+	Products []*domain.Product `json:"products"`
+	Missing  []string          `json:"missing"`
+}
+
+type CreateAttributeRequest struct {
+	Code       string   `json:"code" binding:"required"`
+	Name       string   `json:"name" binding:"required"`
+	Type       string   `json:"type" binding:"required,oneof=text number enum boolean"`
+	Options    []string `json:"options"`
+	Filterable *bool    `json:"filterable"`
+}
+
+type SetProductAttributesRequest struct {
+	Attributes map[string]string `json:"attributes"`
+}
+
+type ReorderMediaRequest struct {
+	MediaIDs []uuid.UUID `json:"mediaIds" binding:"required"`
+}
+
+type CreatePriceListRequest struct {
+	Currency  string `json:"currency" binding:"required,len=3"`
+	Market    string `json:"market"`
+	IsDefault bool   `json:"isDefault"`
+}
+
+type SetProductPriceRequest struct {
+	Price float64 `json:"price" binding:"gte=0"`
+}
+
+type SetTaxRateRequest struct {
+	Country  string  `json:"country" binding:"required"`
+	Region   string  `json:"region"`
+	TaxClass string  `json:"taxClass" binding:"required"`
+	Rate     float64 `json:"rate" binding:"gte=0,lte=100"`
+}
+
+type CreatePriceScheduleRequest struct {
+	Price    float64    `json:"price" binding:"gte=0"`
+	StartsAt time.Time  `json:"startsAt" binding:"required"`
+	EndsAt   *time.Time `json:"endsAt"`
+}
+
+type ReviewRequest struct {
+	Rating  int    `json:"rating" binding:"required,min=1,max=5"`
+	Title   string `json:"title"`
+	Comment string `json:"comment"`
+}
+
+type ReviewListResponse struct {
+	Items    []*domain.Review `json:"items"`
+	Total    int64            `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"pageSize"`
+}
+
+type ReserveStockRequest struct {
+	ReferenceID string                `json:"referenceId" binding:"required"`
+	TTLSeconds  int                   `json:"ttlSeconds" binding:"gte=0"`
+	Items       []ReservationItemData `json:"items" binding:"required,min=1,dive"`
+}
+
+type ReservationItemData struct {
+	ProductID uuid.UUID `json:"productId" binding:"required"`
+	Quantity  int       `json:"quantity" binding:"required,gt=0"`
+}
+
+// SetBundleComponentsRequest — полный состав набора; пустой список делает товар обычным
+type SetBundleComponentsRequest struct {
+	Components []BundleComponentData `json:"components" binding:"dive"`
+}
+
+type BundleComponentData struct {
+	ProductID uuid.UUID `json:"productId" binding:"required"`
+	Quantity  int       `json:"quantity" binding:"required,gt=0"`
+}
+
+// SlugRedirectResponse возвращается по прежнему slug переименованного товара
+type SlugRedirectResponse struct {
+	ProductID uuid.UUID `json:"productId"`
+	Slug      string    `json:"slug"`
+}
+
+type CreateWarehouseRequest struct {
+	Code     string `json:"code" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	Priority int    `json:"priority"`
+}
+
+// UpdateWarehouseRequest меняет только переданные поля склада
+type UpdateWarehouseRequest struct {
+	Name     *string `json:"name"`
+	Priority *int    `json:"priority"`
+	Active   *bool   `json:"active"`
+}
+
+type StockAdjustmentRequest struct {
+	ProductID   uuid.UUID `json:"productId" binding:"required"`
+	WarehouseID uuid.UUID `json:"warehouseId" binding:"required"`
+	Delta       int       `json:"delta" binding:"required"`
+	Reason      string    `json:"reason" binding:"required,oneof=received returned damaged lost stock_count correction"`
+	Note        string    `json:"note"`
+}
+
+type StockTransferRequest struct {
+	ProductID       uuid.UUID `json:"productId" binding:"required"`
+	FromWarehouseID uuid.UUID `json:"fromWarehouseId" binding:"required"`
+	ToWarehouseID   uuid.UUID `json:"toWarehouseId" binding:"required"`
+	Quantity        int       `json:"quantity" binding:"required,gt=0"`
+	Note            string    `json:"note"`
+}
+
+type SetTranslationRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type GraphQLRequest struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type GraphQLResponse struct {
+	Data   interface{}    `json:"data,omitempty"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+type GraphQLError struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// productETag строится из версии товара. Остаток набора вычисляется из компонентов
+// и меняется без смены версии, поэтому у набора он тоже входит в ETag: "3-12".
+func productETag(product *domain.Product) string {
+	if product.IsBundle() {
+		return `"` + strconv.Itoa(product.Version) + "-" + strconv.Itoa(product.Stock) + `"`
+	}
+	return `"` + strconv.Itoa(product.Version) + `"`
+}
+
+// representationETag дополняет версию товара хэшем отданного тела: "3-5f1c...". Цены прайс-листов,
+// налоги, переводы и рейтинг меняют ответ без смены версии, как и валюта, язык и страна доставки
+// запроса. Версия остается в начале, чтобы ETag из GET подходил для If-Match.
+func representationETag(product *domain.Product, body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + strconv.Itoa(product.Version) + "-" + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// parseETagVersion извлекает версию товара из значения ETag вида "3", W/"3", "3-12" или "3-5f1c..."
+func parseETagVersion(tag string) (int, bool) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+
+	value, _, _ := strings.Cut(tag[1:len(tag)-1], "-")
+	version, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return version, true
+}
+
+// requireIfMatch возвращает версию из заголовка If-Match.
+// Без заголовка отвечает 428, при неразборчивом значении — 412.
+func requireIfMatch(c *gin.Context) (int, bool) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header required"})
+		return 0, false
+	}
+
+	version, ok := parseETagVersion(header)
+	if !ok {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "If-Match must contain a single product ETag"})
+		return 0, false
+	}
+
+	return version, true
+}
+
+// notModified проверяет If-None-Match и отвечает 304, если ETag клиента актуален
+func notModified(c *gin.Context, etag string) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			c.Header("ETag", etag)
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
+
+// setPublicCache разрешает кэшировать публичный ответ на время жизни серверного кэша:
+// дольше держать его нет смысла, сервер сам может отдавать данные такой давности.
+// Last-Modified не отдается: цены прайс-листов, налоги и переводы меняют ответ без смены
+// UpdatedAt, поэтому проверять свежесть можно только по ETag, построенному из тела ответа.
+func setPublicCache(c *gin.Context, maxAge time.Duration) {
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(maxAge.Seconds())))
+}
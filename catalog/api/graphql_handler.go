@@ -0,0 +1,76 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yangirxd/store-app/catalog/api/dto"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/graph"
+	"net/http"
+)
+
+// @Summary Storefront GraphQL query
+// @Description Run a GraphQL query over products, the basket and the order history of the user. Products are loaded in batches; basket and orders require authentication. Prices and languages are resolved from the same query parameters and headers as the REST endpoints. Queries deeper or more complex than the configured limits are rejected
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param Authorization header string false "Bearer token"
+// @Param Accept-Currency header string false "Currency to resolve prices in"
+// @Param currency query string false "Currency to resolve prices in (overrides Accept-Currency)"
+// @Param market query string false "Market of the price list"
+// @Param country query string false "Destination country (ISO 3166-1 alpha-2) to add tax-inclusive and tax-exclusive prices for"
+// @Param region query string false "Destination region within the country"
+// @Param Accept-Language header string false "Preferred languages of product text"
+// @Param locale query string false "Language of product text (overrides Accept-Language)"
+// @Param input body dto.GraphQLRequest true "GraphQL query"
+// @Success 200 {object} dto.GraphQLResponse
+// @Failure 400 {object} dto.GraphQLResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Router /api/v1/graphql [post]
+func graphqlHandler(server *graph.Server) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.GraphQLRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		options := graph.ProductOptions{
+			Currency: c.Query("currency"),
+			Market:   c.Query("market"),
+			Country:  c.Query("country"),
+			Region:   c.Query("region"),
+		}
+		if options.Currency == "" {
+			options.Currency = c.GetHeader("Accept-Currency")
+		}
+		if value := c.Query("locale"); value != "" {
+			locale, err := domain.ParseLocale(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			options.Locales = []string{locale}
+		} else {
+			options.Locales = domain.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+		}
+
+		result, executed := server.Execute(c.Request.Context(), graph.Request{
+			Query:         req.Query,
+			OperationName: req.OperationName,
+			Variables:     req.Variables,
+			Authorization: c.GetHeader("Authorization"),
+			Options:       options,
+		})
+
+		resp := dto.GraphQLResponse{Data: result.Data}
+		for _, err := range result.Errors {
+			resp.Errors = append(resp.Errors, dto.GraphQLError{Message: err.Message, Path: err.Path})
+		}
+		status := http.StatusOK
+		if !executed {
+			status = http.StatusBadRequest
+		}
+		c.Header("Vary", "Accept-Currency, Accept-Language")
+		c.JSON(status, resp)
+	}
+}
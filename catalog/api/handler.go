@@ -1,15 +1,22 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/yangirxd/store-app/catalog/api/dto"
 	_ "github.com/yangirxd/store-app/catalog/docs"
+	"github.com/yangirxd/store-app/catalog/domain"
 	"github.com/yangirxd/store-app/catalog/service"
+	"gorm.io/gorm"
+	"io"
 	"net/http"
+	"strings"
+	"time"
 )
 
 // @Summary Create a new product
-// @Description Create a new product in the catalog (requires authentication)
+// @Description Create a new product in the catalog; the authenticated user becomes its seller (requires authentication)
 // @Tags products
 // @Accept json
 // @Produce json
@@ -27,11 +34,16 @@ func createProductHandler(catalogService *service.CatalogService) gin.HandlerFun
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		product, err := catalogService.CreateProduct(req.Name, req.Description, req.Price, req.Stock)
+		product, err := catalogService.CreateProduct(req.SKU, req.Name, req.Description, req.Price, req.Stock, req.Status, req.TaxClass, c.GetString("email"))
 		if err != nil {
+			if errors.Is(err, domain.ErrInvalidProduct) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
+		c.Header("ETag", productETag(product))
 		c.JSON(http.StatusCreated, product)
 	}
 }
@@ -41,91 +53,350 @@ func createProductHandler(catalogService *service.CatalogService) gin.HandlerFun
 // @Tags products
 // @Produce json
 // @Param id path string true "Product ID"
+// @Param Accept-Currency header string false "Currency to resolve prices in"
+// @Param currency query string false "Currency to resolve prices in (overrides Accept-Currency)"
+// @Param market query string false "Market of the price list"
+// @Param country query string false "Destination country (ISO 3166-1 alpha-2) to add tax-inclusive and tax-exclusive prices for"
+// @Param region query string false "Destination region within the country"
+// @Param Accept-Language header string false "Preferred languages of product text"
+// @Param locale query string false "Language of product text (overrides Accept-Language)"
+// @Param If-None-Match header string false "ETag of a cached representation"
 // @Success 200 {object} domain.Product
+// @Success 304 {string} string "Not Modified"
+// @Header 200 {string} ETag "Product version and a hash of the response body"
 // @Failure 400 {string} string "Bad Request"
 // @Failure 401 {string} string "Unauthorized"
 // @Failure 404 {string} string "Product not found"
 // @Failure 500 {string} string "Internal Server Error"
 // @Router /api/v1/products/{id} [get]
-func getProductHandler(catalogService *service.CatalogService) gin.HandlerFunc {
+func getProductHandler(catalogService *service.CatalogService, pricingService *service.PricingService, translationService *service.TranslationService, cacheTTL time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		product, err := catalogService.GetProductByID(id)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+			productError(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, product)
+		writePublicProduct(c, pricingService, translationService, cacheTTL, product)
+	}
+}
+
+// @Summary Get product by slug
+// @Description Get details of a public product by its slug. A former slug of a renamed product answers 301 with the current slug in Location.
+// @Tags products
+// @Produce json
+// @Param slug path string true "Product slug"
+// @Param Accept-Currency header string false "Currency to resolve prices in"
+// @Param currency query string false "Currency to resolve prices in (overrides Accept-Currency)"
+// @Param market query string false "Market of the price list"
+// @Param country query string false "Destination country (ISO 3166-1 alpha-2) to add tax-inclusive and tax-exclusive prices for"
+// @Param region query string false "Destination region within the country"
+// @Param Accept-Language header string false "Preferred languages of product text"
+// @Param locale query string false "Language of product text (overrides Accept-Language)"
+// @Param If-None-Match header string false "ETag of a cached representation"
+// @Success 200 {object} domain.Product
+// @Success 301 {object} dto.SlugRedirectResponse
+// @Success 304 {string} string "Not Modified"
+// @Header 200 {string} ETag "Product version and a hash of the response body"
+// @Header 301 {string} Location "URL of the current slug"
+// @Failure 404 {string} string "Product not found"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/products/by-slug/{slug} [get]
+func getProductBySlugHandler(catalogService *service.CatalogService, pricingService *service.PricingService, translationService *service.TranslationService, cacheTTL time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		slug := c.Param("slug")
+		product, err := catalogService.GetProductBySlug(slug)
+		if err != nil {
+			if errors.Is(err, domain.ErrProductNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if product.Slug != slug {
+			// Относительная ссылка сохраняет префикс, под которым сервис опубликован
+			c.Header("Location", "./"+product.Slug)
+			c.JSON(http.StatusMovedPermanently, dto.SlugRedirectResponse{ProductID: product.ID, Slug: product.Slug})
+			return
+		}
+		writePublicProduct(c, pricingService, translationService, cacheTTL, product)
+	}
+}
+
+// writePublicProduct отдает товар публичного каталога с заголовками кэширования,
+// ценой в валюте запроса и текстом на языке запроса
+func writePublicProduct(c *gin.Context, pricingService *service.PricingService, translationService *service.TranslationService, cacheTTL time.Duration, product *domain.Product) {
+	// Цена и текст зависят от заголовков, поэтому кэши должны различать ответы по ним
+	c.Header("Vary", "Accept-Currency, Accept-Language")
+	setPublicCache(c, cacheTTL)
+	products := []*domain.Product{product}
+	if !applyPrices(c, pricingService, products) || !localize(c, translationService, products) {
+		return
+	}
+	// ETag считается по готовому телу, поэтому 304 отдается только за тот же ответ
+	body, err := json.Marshal(product)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	etag := representationETag(product, body)
+	if notModified(c, etag) {
+		return
 	}
+	c.Header("ETag", etag)
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
 }
 
 // @Summary Get all products
 // @Description Get a list of all products (public endpoint)
 // @Tags products
 // @Produce json
+// @Param Accept-Currency header string false "Currency to resolve prices in"
+// @Param currency query string false "Currency to resolve prices in (overrides Accept-Currency)"
+// @Param market query string false "Market of the price list"
+// @Param country query string false "Destination country (ISO 3166-1 alpha-2) to add tax-inclusive and tax-exclusive prices for"
+// @Param region query string false "Destination region within the country"
+// @Param Accept-Language header string false "Preferred languages of product text"
+// @Param locale query string false "Language of product text (overrides Accept-Language)"
+// @Param attr[code] query string false "Attribute filter, e.g. attr[brand]=acme; comma-separated values are alternatives"
 // @Success 200 {array} domain.Product
+// @Failure 400 {string} string "Bad Request"
 // @Failure 500 {string} string "Internal Server Error"
 // @Router /api/v1/products [get]
-func getAllProductsHandler(catalogService *service.CatalogService) gin.HandlerFunc {
+func getAllProductsHandler(catalogService *service.CatalogService, pricingService *service.PricingService, attributeService *service.AttributeService, translationService *service.TranslationService, cacheTTL time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		products, err := catalogService.GetAllProducts()
+		attributes, ok := attributeFilter(c, attributeService)
+		if !ok {
+			return
+		}
+		products, err := catalogService.GetAllProducts(attributes)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
+		if !applyPrices(c, pricingService, products) || !localize(c, translationService, products) {
+			return
+		}
+		c.Header("Vary", "Accept-Currency, Accept-Language")
+		setPublicCache(c, cacheTTL)
 		c.JSON(http.StatusOK, products)
 	}
 }
 
+// @Summary Get products in batch
+// @Description Get up to 100 products by ID in one request. Accepts a user token or a service token in X-Service-Token. Users get only public products; services also get draft and archived products, with their status.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param Authorization header string false "Bearer token"
+// @Param X-Service-Token header string false "Service token"
+// @Param Accept-Currency header string false "Currency to resolve prices in"
+// @Param currency query string false "Currency to resolve prices in (overrides Accept-Currency)"
+// @Param market query string false "Market of the price list"
+// @Param country query string false "Destination country (ISO 3166-1 alpha-2) to add tax-inclusive and tax-exclusive prices for"
+// @Param region query string false "Destination region within the country"
+// @Param Accept-Language header string false "Preferred languages of product text"
+// @Param locale query string false "Language of product text (overrides Accept-Language)"
+// @Param input body dto.BatchGetProductsRequest true "Product IDs"
+// @Success 200 {object} dto.BatchGetProductsResponse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/products:batchGet [post]
+func batchGetProductsHandler(catalogService *service.CatalogService, pricingService *service.PricingService, translationService *service.TranslationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.BatchGetProductsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		products, missing, err := catalogService.GetProductsByIDs(req.IDs, actorFrom(c))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !applyPrices(c, pricingService, products) || !localize(c, translationService, products) {
+			return
+		}
+		c.JSON(http.StatusOK, dto.BatchGetProductsResponse{Products: products, Missing: missing})
+	}
+}
+
+// productMethodHandler обслуживает пути вида /products:<метод>. Gin не умеет
+// экранировать двоеточие, поэтому маршрут объявлен как параметр и метод проверяется здесь.
+func productMethodHandler(methods map[string]gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handler, ok := methods[strings.TrimPrefix(c.Param("method"), ":")]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		handler(c)
+	}
+}
+
 // @Summary Update a product
-// @Description Update details of an existing product (requires authentication)
+// @Description Update details of an existing product; sellers may update only their own products, admins any (requires authentication)
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer token"
+// @Param If-Match header string true "ETag of the product being updated"
 // @Param id path string true "Product ID"
 // @Param input body dto.UpdateProductRequest true "Updated product data"
 // @Success 200 {object} domain.Product
+// @Header 200 {string} ETag "New product version"
 // @Failure 400 {string} string "Bad Request"
 // @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Product belongs to another seller"
 // @Failure 404 {string} string "Product not found"
+// @Failure 412 {string} string "Precondition Failed"
+// @Failure 428 {string} string "Precondition Required"
 // @Failure 500 {string} string "Internal Server Error"
 // @Router /api/v1/products/{id} [put]
 func updateProductHandler(catalogService *service.CatalogService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
+		version, ok := requireIfMatch(c)
+		if !ok {
+			return
+		}
 		var req dto.UpdateProductRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		product, err := catalogService.UpdateProduct(id, req.Name, req.Description, req.Price, req.Stock)
+		product, err := catalogService.UpdateProduct(id, version, actorFrom(c), req.Name, req.Description, req.Price, req.Stock)
+		if err != nil {
+			productError(c, err)
+			return
+		}
+		c.Header("ETag", productETag(product))
+		c.JSON(http.StatusOK, product)
+	}
+}
+
+// @Summary Partially update a product
+// @Description Apply a JSON Merge Patch (application/merge-patch+json) or JSON Patch (application/json-patch+json) to a product; sellers may patch only their own products, admins any (requires authentication)
+// @Tags products
+// @Accept json
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param If-Match header string true "ETag of the product being updated"
+// @Param id path string true "Product ID"
+// @Param input body object true "Merge patch document or array of JSON Patch operations"
+// @Success 200 {object} domain.Product
+// @Header 200 {string} ETag "New product version"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Product belongs to another seller"
+// @Failure 404 {string} string "Product not found"
+// @Failure 412 {string} string "Precondition Failed"
+// @Failure 415 {string} string "Unsupported Media Type"
+// @Failure 422 {string} string "JSON Patch test failed"
+// @Failure 428 {string} string "Precondition Required"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/products/{id} [patch]
+func patchProductHandler(catalogService *service.CatalogService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		version, ok := requireIfMatch(c)
+		if !ok {
+			return
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var patch domain.Patch
+		switch c.ContentType() {
+		case "application/json-patch+json":
+			patch, err = domain.ParseJSONPatch(body)
+		case "application/merge-patch+json", "application/json":
+			patch, err = domain.ParseMergePatch(body)
+		default:
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "use application/merge-patch+json or application/json-patch+json"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		product, err := catalogService.PatchProduct(id, version, actorFrom(c), patch)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+			productError(c, err)
 			return
 		}
+		c.Header("ETag", productETag(product))
 		c.JSON(http.StatusOK, product)
 	}
 }
 
 // @Summary Delete a product
-// @Description Delete a product by its UUID (requires authentication)
+// @Description Soft-delete a product by its UUID; it can be restored later. Sellers may delete only their own products, admins any (requires authentication)
 // @Tags products
 // @Param Authorization header string true "Bearer token"
+// @Param If-Match header string true "ETag of the product being deleted"
 // @Param id path string true "Product ID"
 // @Success 204 {string} string "No Content"
 // @Failure 400 {string} string "Bad Request"
 // @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Product belongs to another seller"
 // @Failure 404 {string} string "Product not found"
+// @Failure 412 {string} string "Precondition Failed"
+// @Failure 428 {string} string "Precondition Required"
 // @Failure 500 {string} string "Internal Server Error"
 // @Router /api/v1/products/{id} [delete]
 func deleteProductHandler(catalogService *service.CatalogService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
-		if err := catalogService.DeleteProduct(id); err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+		version, ok := requireIfMatch(c)
+		if !ok {
+			return
+		}
+		if err := catalogService.DeleteProduct(id, version, actorFrom(c)); err != nil {
+			productError(c, err)
 			return
 		}
 		c.JSON(http.StatusNoContent, gin.H{})
 	}
 }
+
+// actorFrom возвращает пользователя, которого аутентифицировал CatalogMiddleware, или сервис,
+// пропущенный ServiceOrUserMiddleware
+func actorFrom(c *gin.Context) domain.Actor {
+	return domain.Actor{Email: c.GetString("email"), IsAdmin: c.GetBool("isAdmin"), IsService: c.GetBool("isService")}
+}
+
+// productError отвечает на ошибку изменения товара. Ошибки валидации — вина клиента,
+// остальное, кроме отсутствующего товара, — ошибка сервера.
+func productError(c *gin.Context, err error) {
+	c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+}
+
+func productErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrVersionConflict):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, domain.ErrNotProductOwner):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrProductNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrPatchTestFailed):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrInvalidProduct),
+		errors.Is(err, domain.ErrInvalidPatch),
+		errors.Is(err, domain.ErrInvalidBundle),
+		errors.Is(err, domain.ErrInvalidAttribute),
+		errors.Is(err, domain.ErrInvalidID):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
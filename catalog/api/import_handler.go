@@ -0,0 +1,138 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/service"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxImportSize ограничивает размер загружаемого файла импорта
+const maxImportSize = 100 << 20
+
+// requestFormat определяет формат по query-параметру format, иначе по Content-Type или Accept
+func requestFormat(c *gin.Context, header string) string {
+	if format := c.Query("format"); format != "" {
+		return format
+	}
+
+	// Accept может перечислять несколько типов; параметры вроде charset на формат не влияют
+	for _, value := range strings.Split(c.GetHeader(header), ",") {
+		mediaType, _, _ := mime.ParseMediaType(value)
+		switch mediaType {
+		case "application/x-ndjson", "application/ndjson":
+			return "ndjson"
+		}
+	}
+
+	return "csv"
+}
+
+// @Summary Import products
+// @Description Import products from a CSV or NDJSON stream as a background job, upserting by SKU. Every row needs sku, name and price; other columns missing from the file keep their current values on update (requires authentication)
+// @Tags import
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param format query string false "csv or ndjson (defaults to Content-Type)"
+// @Param dryRun query bool false "Validate rows without saving"
+// @Success 202 {object} domain.ImportJob
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 413 {string} string "Request Entity Too Large"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/products/import [post]
+func importProductsHandler(importService *service.ImportService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dryRun, _ := strconv.ParseBool(c.Query("dryRun"))
+		body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+		job, err := importService.StartImport(requestFormat(c, "Content-Type"), dryRun, c.GetString("email"), body)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Location", fmt.Sprintf("/api/v1/products/import/%s", job.ID))
+		c.JSON(http.StatusAccepted, job)
+	}
+}
+
+func importJobErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrImportJobNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrImportJobForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrInvalidID):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// @Summary Get import job
+// @Description Get the status and per-row error report of an import job; users see only their own jobs, admins any (requires authentication)
+// @Tags import
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param jobID path string true "Import job ID"
+// @Success 200 {object} domain.ImportJob
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Import job not found"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/products/import/{jobID} [get]
+func getImportJobHandler(importService *service.ImportService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := importService.GetImportJob(c.Param("jobID"), actorFrom(c))
+		if err != nil {
+			c.JSON(importJobErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, job)
+	}
+}
+
+// @Summary Export products
+// @Description Stream products as CSV or NDJSON; sellers export their own products, admins the whole catalog (requires authentication)
+// @Tags import
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param Authorization header string true "Bearer token"
+// @Param format query string false "csv or ndjson (defaults to Accept)"
+// @Success 200 {string} string "Product rows"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Router /api/v1/products/export [get]
+func exportProductsHandler(importService *service.ImportService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := requestFormat(c, "Accept")
+		contentType := "text/csv"
+		if format == "ndjson" {
+			contentType = "application/x-ndjson"
+		} else if format != "csv" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported format %q", format)})
+			return
+		}
+
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=products.%s", format))
+		c.Status(http.StatusOK)
+		if err := importService.ExportProducts(format, actorFrom(c), c.Writer); err != nil {
+			// Заголовки уже отправлены, поэтому только логируем
+			log.Printf("Failed to export products: %v", err)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/yangirxd/store-app/catalog/api/dto"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/service"
+	"github.com/yangirxd/store-app/catalog/storage"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// maxMediaSize ограничивает размер загружаемого изображения
+const maxMediaSize = 10 << 20
+
+func mediaErrorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, domain.ErrUnsupportedMedia):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, domain.ErrProductNotFound), errors.Is(err, domain.ErrMediaNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrNotProductOwner):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrInvalidMediaOrder):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// @Summary Upload product media
+// @Description Upload a JPEG, PNG or GIF image of a product as multipart field "file"; a thumbnail is generated (requires authentication)
+// @Tags media
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param file formData file true "Image file"
+// @Success 201 {object} domain.ProductMedia
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Product belongs to another seller"
+// @Failure 404 {string} string "Product not found"
+// @Failure 413 {string} string "Request Entity Too Large"
+// @Failure 415 {string} string "Unsupported Media Type"
+// @Router /api/v1/products/{id}/media [post]
+func uploadMediaHandler(mediaService *service.MediaService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Запас на заголовки multipart сверх размера самого файла
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxMediaSize+64<<10)
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			status := mediaErrorStatus(err)
+			if status == http.StatusInternalServerError {
+				status = http.StatusBadRequest
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		if fileHeader.Size > maxMediaSize {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file is too large"})
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer file.Close()
+
+		media, err := mediaService.UploadMedia(c.Param("id"), actorFrom(c), file)
+		if err != nil {
+			c.JSON(mediaErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Location", media.URL)
+		c.JSON(http.StatusCreated, media)
+	}
+}
+
+// @Summary Get product media
+// @Description Get product images in display order
+// @Tags media
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {array} domain.ProductMedia
+// @Failure 400 {string} string "Bad Request"
+// @Router /api/v1/products/{id}/media [get]
+func getMediaHandler(mediaService *service.MediaService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		media, err := mediaService.GetMedia(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, media)
+	}
+}
+
+// @Summary Reorder product media
+// @Description Set the display order of all product images; the first one becomes the main image (requires authentication)
+// @Tags media
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param input body dto.ReorderMediaRequest true "Media IDs in display order"
+// @Success 200 {array} domain.ProductMedia
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Product belongs to another seller"
+// @Failure 404 {string} string "Product not found"
+// @Router /api/v1/products/{id}/media/order [put]
+func reorderMediaHandler(mediaService *service.MediaService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ReorderMediaRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		media, err := mediaService.ReorderMedia(c.Param("id"), actorFrom(c), req.MediaIDs)
+		if err != nil {
+			c.JSON(mediaErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, media)
+	}
+}
+
+// @Summary Delete product media
+// @Description Delete a product image and its thumbnail (requires authentication)
+// @Tags media
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param mediaID path string true "Media ID"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Product belongs to another seller"
+// @Failure 404 {string} string "Media not found"
+// @Router /api/v1/products/{id}/media/{mediaID} [delete]
+func deleteMediaHandler(mediaService *service.MediaService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := mediaService.DeleteMedia(c.Param("id"), c.Param("mediaID"), actorFrom(c)); err != nil {
+			status := mediaErrorStatus(err)
+			if status == http.StatusInternalServerError {
+				status = http.StatusBadRequest
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNoContent, gin.H{})
+	}
+}
+
+// @Summary Serve media file
+// @Description Serve an uploaded image or thumbnail. Files never change, so they are cached for a year.
+// @Tags media
+// @Produce image/jpeg
+// @Produce image/png
+// @Produce image/gif
+// @Param key path string true "Media key"
+// @Success 200 {file} file
+// @Success 304 {string} string "Not Modified"
+// @Failure 404 {string} string "Media not found"
+// @Router /api/v1/media/{key} [get]
+func serveMediaHandler(mediaService *service.MediaService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimPrefix(c.Param("key"), "/")
+		blob, err := mediaService.OpenBlob(key)
+		if err != nil {
+			if errors.Is(err, storage.ErrBlobNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "media not found"})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer blob.Close()
+
+		// Ключ включает UUID изображения: по одному ключу всегда один и тот же файл
+		c.Header("Cache-Control", "public, max-age=31536000, immutable")
+		c.Header("ETag", `"`+path.Base(key)+`"`)
+		c.Header("X-Content-Type-Options", "nosniff")
+		http.ServeContent(c.Writer, c.Request, path.Base(key), blob.ModTime, blob)
+	}
+}
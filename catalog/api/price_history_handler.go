@@ -0,0 +1,136 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/yangirxd/store-app/catalog/api/dto"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/service"
+	"net/http"
+	"strconv"
+)
+
+func priceScheduleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrProductNotFound), errors.Is(err, domain.ErrScheduleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrNotProductOwner):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrScheduleOverlap):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidSchedule), errors.Is(err, domain.ErrInvalidID):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// @Summary Get product price history
+// @Description Get the history of base price changes of a product, newest first
+// @Tags prices
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param limit query int false "Maximum number of entries (default 100)"
+// @Success 200 {array} domain.PriceHistory
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "Product not found"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/products/{id}/prices [get]
+func getPriceHistoryHandler(priceHistoryService *service.PriceHistoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := 0
+		if value := c.Query("limit"); value != "" {
+			var err error
+			if limit, err = strconv.Atoi(value); err != nil || limit < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+				return
+			}
+		}
+		history, err := priceHistoryService.GetPriceHistory(c.Param("id"), limit)
+		if err != nil {
+			c.JSON(priceScheduleErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, history)
+	}
+}
+
+// @Summary Schedule a product price
+// @Description Schedule a base price for a period; the previous price is restored when it ends; sellers may schedule prices only of their own products, admins of any (requires authentication)
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param input body dto.CreatePriceScheduleRequest true "Scheduled price"
+// @Success 201 {object} domain.PriceSchedule
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not the product owner"
+// @Failure 404 {string} string "Product not found"
+// @Failure 409 {string} string "Overlaps an existing schedule"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/products/{id}/price-schedules [post]
+func createPriceScheduleHandler(priceHistoryService *service.PriceHistoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.CreatePriceScheduleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		schedule, err := priceHistoryService.SchedulePrice(c.Param("id"), req.Price, req.StartsAt, req.EndsAt, actorFrom(c))
+		if err != nil {
+			c.JSON(priceScheduleErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, schedule)
+	}
+}
+
+// @Summary Get product price schedules
+// @Description Get all scheduled prices of a product; sellers see schedules only of their own products, admins of any (requires authentication)
+// @Tags prices
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Success 200 {array} domain.PriceSchedule
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not the product owner"
+// @Failure 404 {string} string "Product not found"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/products/{id}/price-schedules [get]
+func getPriceSchedulesHandler(priceHistoryService *service.PriceHistoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		schedules, err := priceHistoryService.GetPriceSchedules(c.Param("id"), actorFrom(c))
+		if err != nil {
+			c.JSON(priceScheduleErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, schedules)
+	}
+}
+
+// @Summary Cancel a price schedule
+// @Description Cancel a pending schedule or end an active one now; sellers may cancel schedules only of their own products, admins any (requires authentication)
+// @Tags prices
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param scheduleID path string true "Schedule ID"
+// @Success 200 {object} domain.PriceSchedule
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not the product owner"
+// @Failure 404 {string} string "Schedule not found"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/price-schedules/{scheduleID} [delete]
+func cancelPriceScheduleHandler(priceHistoryService *service.PriceHistoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		schedule, err := priceHistoryService.CancelPriceSchedule(c.Param("scheduleID"), actorFrom(c))
+		if err != nil {
+			c.JSON(priceScheduleErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, schedule)
+	}
+}
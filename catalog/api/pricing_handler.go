@@ -0,0 +1,234 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/yangirxd/store-app/catalog/api/dto"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/service"
+	"net/http"
+)
+
+// applyPrices пересчитывает цены по валюте из query-параметра currency или заголовка Accept-Currency
+// и, если передана страна доставки country (и регион region), добавляет цены с налогом и без него
+func applyPrices(c *gin.Context, pricingService *service.PricingService, products []*domain.Product) bool {
+	currency := c.Query("currency")
+	if currency == "" {
+		currency = c.GetHeader("Accept-Currency")
+	}
+
+	err := pricingService.ApplyPrices(products, currency, c.Query("market"))
+	if err == nil {
+		err = pricingService.ApplyTaxes(products, c.Query("country"), c.Query("region"))
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrUnsupportedCurrency) || errors.Is(err, domain.ErrInvalidTaxRate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+
+	return true
+}
+
+func priceListErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrPriceListNotFound), errors.Is(err, domain.ErrProductNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrNotProductOwner):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrPriceListExists):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidPriceList), errors.Is(err, domain.ErrInvalidID):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// @Summary Create a price list
+// @Description Create a price list for a currency and market (requires admin)
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param input body dto.CreatePriceListRequest true "Price list data"
+// @Success 201 {object} domain.PriceList
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 409 {string} string "Price list already exists"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/admin/price-lists [post]
+func createPriceListHandler(pricingService *service.PricingService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.CreatePriceListRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		priceList, err := pricingService.CreatePriceList(req.Currency, req.Market, req.IsDefault)
+		if err != nil {
+			c.JSON(priceListErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, priceList)
+	}
+}
+
+// @Summary Get all price lists
+// @Description Get all configured price lists (requires authentication)
+// @Tags prices
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {array} domain.PriceList
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/price-lists [get]
+func getPriceListsHandler(pricingService *service.PricingService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		priceLists, err := pricingService.GetPriceLists()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, priceLists)
+	}
+}
+
+// @Summary Set product price in a price list
+// @Description Override the price of a product in a price list; sellers may set prices only of their own products, admins of any (requires authentication)
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Price list ID"
+// @Param productID path string true "Product ID"
+// @Param input body dto.SetProductPriceRequest true "Price"
+// @Success 200 {object} domain.PriceListEntry
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not the product owner"
+// @Failure 404 {string} string "Price list or product not found"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/price-lists/{id}/prices/{productID} [put]
+func setProductPriceHandler(pricingService *service.PricingService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.SetProductPriceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		entry, err := pricingService.SetProductPrice(c.Param("id"), c.Param("productID"), req.Price, actorFrom(c))
+		if err != nil {
+			c.JSON(priceListErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, entry)
+	}
+}
+
+// @Summary Remove product price from a price list
+// @Description Remove a per-product price override; sellers may remove prices only of their own products, admins of any (requires authentication)
+// @Tags prices
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Price list ID"
+// @Param productID path string true "Product ID"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not the product owner"
+// @Failure 404 {string} string "Price list or product not found"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/price-lists/{id}/prices/{productID} [delete]
+func deleteProductPriceHandler(pricingService *service.PricingService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := pricingService.DeleteProductPrice(c.Param("id"), c.Param("productID"), actorFrom(c)); err != nil {
+			c.JSON(priceListErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNoContent, gin.H{})
+	}
+}
+
+// @Summary Get tax rates
+// @Description Get the tax rates per country, region and tax class. A rate without a region applies to the whole country unless the region has its own rate
+// @Tags prices
+// @Produce json
+// @Success 200 {array} domain.TaxRate
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/tax-rates [get]
+func getTaxRatesHandler(pricingService *service.PricingService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rates, err := pricingService.GetTaxRates()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, rates)
+	}
+}
+
+// @Summary Set a tax rate
+// @Description Create or replace the rate of a tax class for a country or one of its regions (requires admin role)
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param input body dto.SetTaxRateRequest true "Tax rate"
+// @Success 200 {object} domain.TaxRate
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/admin/tax-rates [put]
+func setTaxRateHandler(pricingService *service.PricingService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.SetTaxRateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		rate, err := pricingService.SetTaxRate(req.Country, req.Region, req.TaxClass, req.Rate)
+		if err != nil {
+			c.JSON(taxRateErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, rate)
+	}
+}
+
+// @Summary Delete a tax rate
+// @Description Delete a tax rate (requires admin role)
+// @Tags prices
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Tax rate ID"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Tax rate not found"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/admin/tax-rates/{id} [delete]
+func deleteTaxRateHandler(pricingService *service.PricingService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := pricingService.DeleteTaxRate(c.Param("id")); err != nil {
+			c.JSON(taxRateErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNoContent, gin.H{})
+	}
+}
+
+func taxRateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrTaxRateNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidTaxRate), errors.Is(err, domain.ErrInvalidID):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/service"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// @Summary Get related products
+// @Description Get public products frequently bought together with the product, most frequent first. If there are too few, the list is filled up with products of the same category
+// @Tags products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param limit query int false "Maximum number of products (default 10, max 50)"
+// @Param Accept-Currency header string false "Currency to resolve prices in"
+// @Param currency query string false "Currency to resolve prices in (overrides Accept-Currency)"
+// @Param market query string false "Market of the price list"
+// @Param country query string false "Destination country (ISO 3166-1 alpha-2) to add tax-inclusive and tax-exclusive prices for"
+// @Param region query string false "Destination region within the country"
+// @Param Accept-Language header string false "Preferred languages of product text"
+// @Param locale query string false "Language of product text (overrides Accept-Language)"
+// @Success 200 {array} domain.Product
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "Product not found"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/products/{id}/related [get]
+func getRelatedProductsHandler(recommendationService *service.RecommendationService, pricingService *service.PricingService, translationService *service.TranslationService, cacheTTL time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := 0
+		if value := c.Query("limit"); value != "" {
+			var err error
+			if limit, err = strconv.Atoi(value); err != nil || limit < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+				return
+			}
+		}
+		products, err := recommendationService.GetRelatedProducts(c.Param("id"), limit)
+		if err != nil {
+			if errors.Is(err, domain.ErrProductNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !applyPrices(c, pricingService, products) || !localize(c, translationService, products) {
+			return
+		}
+		c.Header("Vary", "Accept-Currency, Accept-Language")
+		setPublicCache(c, cacheTTL)
+		c.JSON(http.StatusOK, products)
+	}
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/service"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// @Summary Get replenishment report
+// @Description Get products whose stock fell to the reorder threshold, with quantities to reorder based on sales over the last days (requires admin)
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param days query int false "Sales window in days (default 30, max 365)"
+// @Success 200 {array} domain.ReplenishmentLine
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/admin/replenishment [get]
+func getReplenishmentReportHandler(replenishmentService *service.ReplenishmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lines, ok := replenishmentReport(c, replenishmentService)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, lines)
+	}
+}
+
+// @Summary Export replenishment report
+// @Description Download the replenishment report as CSV for purchasing (requires admin)
+// @Tags admin
+// @Produce text/csv
+// @Param Authorization header string true "Bearer token"
+// @Param days query int false "Sales window in days (default 30, max 365)"
+// @Success 200 {string} string "Report rows"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/admin/replenishment/export [get]
+func exportReplenishmentReportHandler(replenishmentService *service.ReplenishmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Отчет строится до отправки заголовков, чтобы ошибку можно было вернуть как JSON
+		lines, ok := replenishmentReport(c, replenishmentService)
+		if !ok {
+			return
+		}
+
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", "attachment; filename=replenishment.csv")
+		c.Status(http.StatusOK)
+		if err := replenishmentService.ExportReport(lines, c.Writer); err != nil {
+			log.Printf("Failed to export replenishment report: %v", err)
+		}
+	}
+}
+
+func replenishmentReport(c *gin.Context, replenishmentService *service.ReplenishmentService) ([]domain.ReplenishmentLine, bool) {
+	days := 0
+	if value := c.Query("days"); value != "" {
+		var err error
+		if days, err = strconv.Atoi(value); err != nil || days <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid days"})
+			return nil, false
+		}
+	}
+	lines, err := replenishmentService.GetReport(days)
+	if err != nil {
+		c.JSON(replenishmentErrorStatus(err), gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return lines, true
+}
+
+func replenishmentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidSalesWindow):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
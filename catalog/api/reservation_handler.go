@@ -0,0 +1,141 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/yangirxd/store-app/catalog/api/dto"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/service"
+	"net/http"
+	"time"
+)
+
+func reservationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrReservationNotFound), errors.Is(err, domain.ErrProductNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrReservationForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrInvalidReservation):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrReservationLimit):
+		return http.StatusTooManyRequests
+	case errors.Is(err, domain.ErrInsufficientStock),
+		errors.Is(err, domain.ErrReservationNotActive),
+		errors.Is(err, domain.ErrReservationDuplicated):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// @Summary Reserve stock
+// @Description Reserve stock of products for a reference ID with a TTL. Accepts a user token or a service token in X-Service-Token; a reservation made by a user is available only to that user. Users may reserve at most 10 of each product for at most 15 minutes and hold at most 3 active reservations.
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param Authorization header string false "Bearer token"
+// @Param X-Service-Token header string false "Service token"
+// @Param input body dto.ReserveStockRequest true "Reservation data"
+// @Success 201 {object} domain.Reservation
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Product not found"
+// @Failure 409 {string} string "Insufficient stock or duplicated reference"
+// @Failure 429 {string} string "Too many active reservations"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/reservations [post]
+func reserveStockHandler(inventoryService *service.InventoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ReserveStockRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		items := make([]service.ReservationItem, 0, len(req.Items))
+		for _, item := range req.Items {
+			items = append(items, service.ReservationItem{ProductID: item.ProductID, Quantity: item.Quantity})
+		}
+		ttl := time.Duration(req.TTLSeconds) * time.Second
+		reservation, err := inventoryService.ReserveStock(req.ReferenceID, ttl, items, actorFrom(c))
+		if err != nil {
+			c.JSON(reservationErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, reservation)
+	}
+}
+
+// @Summary Get reservation
+// @Description Get a stock reservation by its reference ID. Accepts a user token or a service token in X-Service-Token.
+// @Tags reservations
+// @Produce json
+// @Param Authorization header string false "Bearer token"
+// @Param X-Service-Token header string false "Service token"
+// @Param referenceID path string true "Reference ID"
+// @Success 200 {object} domain.Reservation
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Reservation belongs to another user"
+// @Failure 404 {string} string "Reservation not found"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/reservations/{referenceID} [get]
+func getReservationHandler(inventoryService *service.InventoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reservation, err := inventoryService.GetReservation(c.Param("referenceID"), actorFrom(c))
+		if err != nil {
+			c.JSON(reservationErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, reservation)
+	}
+}
+
+// @Summary Commit reservation
+// @Description Commit an active reservation so its stock is no longer returned. Accepts a user token or a service token in X-Service-Token.
+// @Tags reservations
+// @Produce json
+// @Param Authorization header string false "Bearer token"
+// @Param X-Service-Token header string false "Service token"
+// @Param referenceID path string true "Reference ID"
+// @Success 200 {object} domain.Reservation
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Reservation belongs to another user"
+// @Failure 404 {string} string "Reservation not found"
+// @Failure 409 {string} string "Reservation is not active"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/reservations/{referenceID}/commit [post]
+func commitReservationHandler(inventoryService *service.InventoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reservation, err := inventoryService.CommitReservation(c.Param("referenceID"), actorFrom(c))
+		if err != nil {
+			c.JSON(reservationErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, reservation)
+	}
+}
+
+// @Summary Release reservation
+// @Description Release an active reservation and return its stock. Accepts a user token or a service token in X-Service-Token.
+// @Tags reservations
+// @Produce json
+// @Param Authorization header string false "Bearer token"
+// @Param X-Service-Token header string false "Service token"
+// @Param referenceID path string true "Reference ID"
+// @Success 200 {object} domain.Reservation
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Reservation belongs to another user"
+// @Failure 404 {string} string "Reservation not found"
+// @Failure 409 {string} string "Reservation is not active"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/reservations/{referenceID}/release [post]
+func releaseReservationHandler(inventoryService *service.InventoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reservation, err := inventoryService.ReleaseReservation(c.Param("referenceID"), actorFrom(c))
+		if err != nil {
+			c.JSON(reservationErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, reservation)
+	}
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/yangirxd/store-app/catalog/api/dto"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/service"
+	"net/http"
+	"strconv"
+)
+
+func reviewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrProductNotFound), errors.Is(err, domain.ErrReviewNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrNotPurchased), errors.Is(err, domain.ErrReviewForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrReviewExists):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// @Summary Get product reviews
+// @Description Get a page of product reviews, newest first
+// @Tags reviews
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param page query int false "Page number starting from 1"
+// @Param pageSize query int false "Page size (default 20, max 100)"
+// @Success 200 {object} dto.ReviewListResponse
+// @Failure 400 {string} string "Bad Request"
+// @Router /api/v1/products/{id}/reviews [get]
+func getReviewsHandler(reviewService *service.ReviewService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, _ := strconv.Atoi(c.Query("page"))
+		pageSize, _ := strconv.Atoi(c.Query("pageSize"))
+		result, err := reviewService.GetReviews(c.Param("id"), page, pageSize)
+		if err != nil {
+			c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, dto.ReviewListResponse{
+			Items:    result.Reviews,
+			Total:    result.Total,
+			Page:     result.Page,
+			PageSize: result.PageSize,
+		})
+	}
+}
+
+// @Summary Create a product review
+// @Description Review a product delivered to the authenticated user (requires authentication)
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param input body dto.ReviewRequest true "Review data"
+// @Success 201 {object} domain.Review
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Product was not delivered to the user"
+// @Failure 404 {string} string "Product not found"
+// @Failure 409 {string} string "Review already exists"
+// @Router /api/v1/products/{id}/reviews [post]
+func createReviewHandler(reviewService *service.ReviewService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ReviewRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		review, err := reviewService.CreateReview(c.Param("id"), c.GetString("email"), req.Rating, req.Title, req.Comment)
+		if err != nil {
+			c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, review)
+	}
+}
+
+// @Summary Update a review
+// @Description Edit a review written by the authenticated user (requires authentication)
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param reviewID path string true "Review ID"
+// @Param input body dto.ReviewRequest true "Review data"
+// @Success 200 {object} domain.Review
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Review belongs to another user"
+// @Failure 404 {string} string "Review not found"
+// @Router /api/v1/reviews/{reviewID} [put]
+func updateReviewHandler(reviewService *service.ReviewService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ReviewRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		review, err := reviewService.UpdateReview(c.Param("reviewID"), c.GetString("email"), req.Rating, req.Title, req.Comment)
+		if err != nil {
+			c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, review)
+	}
+}
+
+// @Summary Delete a review
+// @Description Delete a review written by the authenticated user (requires authentication)
+// @Tags reviews
+// @Param Authorization header string true "Bearer token"
+// @Param reviewID path string true "Review ID"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Review belongs to another user"
+// @Failure 404 {string} string "Review not found"
+// @Router /api/v1/reviews/{reviewID} [delete]
+func deleteReviewHandler(reviewService *service.ReviewService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := reviewService.DeleteReview(c.Param("reviewID"), c.GetString("email")); err != nil {
+			c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNoContent, gin.H{})
+	}
+}
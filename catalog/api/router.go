@@ -5,8 +5,10 @@ import (
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	_ "github.com/yangirxd/store-app/catalog/docs"
+	"github.com/yangirxd/store-app/catalog/graph"
 	"github.com/yangirxd/store-app/catalog/middleware"
 	"github.com/yangirxd/store-app/catalog/service"
+	"time"
 )
 
 // @title Catalog API
@@ -14,21 +16,98 @@ import (
 // @description This is a catalog service using DDD and Gin with JWT authentication
 // @host localhost:8081
 // @BasePath /catalog
-func SetupRouter(catalogService *service.CatalogService) *gin.Engine {
+func SetupRouter(catalogService *service.CatalogService, pricingService *service.PricingService, priceHistoryService *service.PriceHistoryService, inventoryService *service.InventoryService, importService *service.ImportService, reviewService *service.ReviewService, attributeService *service.AttributeService, mediaService *service.MediaService, translationService *service.TranslationService, stockSubscriptionService *service.StockSubscriptionService, auditService *service.AuditService, recommendationService *service.RecommendationService, replenishmentService *service.ReplenishmentService, graphServer *graph.Server, cacheTTL time.Duration) *gin.Engine {
 	r := gin.Default()
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	api := r.Group("/api/v1")
 	{
-		api.GET("/products", getAllProductsHandler(catalogService))
-		api.GET("/products/:id", getProductHandler(catalogService))
+		api.GET("/products", getAllProductsHandler(catalogService, pricingService, attributeService, translationService, cacheTTL))
+		api.GET("/products/facets", getProductFacetsHandler(attributeService))
+		// Другие сервисы ходят сюда с X-Service-Token вместо пользовательского JWT
+		api.POST("/products:method", middleware.ServiceOrUserMiddleware(), productMethodHandler(map[string]gin.HandlerFunc{
+			"batchGet": batchGetProductsHandler(catalogService, pricingService, translationService),
+		}))
+		api.GET("/products/:id", getProductHandler(catalogService, pricingService, translationService, cacheTTL))
+		api.GET("/products/by-slug/:slug", getProductBySlugHandler(catalogService, pricingService, translationService, cacheTTL))
+		api.GET("/products/:id/prices", getPriceHistoryHandler(priceHistoryService))
+		api.GET("/products/:id/reviews", getReviewsHandler(reviewService))
+		api.GET("/products/:id/related", getRelatedProductsHandler(recommendationService, pricingService, translationService, cacheTTL))
+		api.GET("/sellers/:id/products", getSellerProductsHandler(catalogService, pricingService, translationService, cacheTTL))
+		api.GET("/attributes", getAttributesHandler(attributeService))
+		api.POST("/graphql", middleware.OptionalUserMiddleware(), graphqlHandler(graphServer))
+		api.GET("/tax-rates", getTaxRatesHandler(pricingService))
+		api.GET("/products/:id/media", getMediaHandler(mediaService))
+		api.GET("/media/*key", serveMediaHandler(mediaService))
+
+		// Резервы создают и подтверждают другие сервисы; пользователь видит только свои резервы
+		reservations := api.Group("/reservations", middleware.ServiceOrUserMiddleware())
+		{
+			reservations.POST("", reserveStockHandler(inventoryService))
+			reservations.GET("/:referenceID", getReservationHandler(inventoryService))
+			reservations.POST("/:referenceID/commit", commitReservationHandler(inventoryService))
+			reservations.POST("/:referenceID/release", releaseReservationHandler(inventoryService))
+		}
 
 		protected := api.Group("", middleware.CatalogMiddleware())
 		{
 			protected.POST("/products", createProductHandler(catalogService))
 			protected.PUT("/products/:id", updateProductHandler(catalogService))
+			protected.PATCH("/products/:id", patchProductHandler(catalogService))
+			protected.POST("/products/import", importProductsHandler(importService))
+			protected.GET("/products/import/:jobID", getImportJobHandler(importService))
+			protected.GET("/products/export", exportProductsHandler(importService))
 			protected.DELETE("/products/:id", deleteProductHandler(catalogService))
+			protected.PUT("/products/:id/attributes", setProductAttributesHandler(attributeService))
+			protected.PUT("/products/:id/components", setBundleComponentsHandler(catalogService))
+			protected.POST("/products/:id/media", uploadMediaHandler(mediaService))
+			protected.PUT("/products/:id/media/order", reorderMediaHandler(mediaService))
+			protected.DELETE("/products/:id/media/:mediaID", deleteMediaHandler(mediaService))
+
+			protected.POST("/products/:id/notify-me", subscribeStockHandler(stockSubscriptionService))
+			protected.DELETE("/products/:id/notify-me", unsubscribeStockHandler(stockSubscriptionService))
+
+			protected.POST("/products/:id/reviews", createReviewHandler(reviewService))
+			protected.PUT("/reviews/:reviewID", updateReviewHandler(reviewService))
+			protected.DELETE("/reviews/:reviewID", deleteReviewHandler(reviewService))
+
+			protected.GET("/price-lists", getPriceListsHandler(pricingService))
+			protected.PUT("/price-lists/:id/prices/:productID", setProductPriceHandler(pricingService))
+			protected.DELETE("/price-lists/:id/prices/:productID", deleteProductPriceHandler(pricingService))
+
+			protected.POST("/products/:id/price-schedules", createPriceScheduleHandler(priceHistoryService))
+			protected.GET("/products/:id/price-schedules", getPriceSchedulesHandler(priceHistoryService))
+			protected.DELETE("/price-schedules/:scheduleID", cancelPriceScheduleHandler(priceHistoryService))
+
+			protected.GET("/warehouses", getWarehousesHandler(inventoryService))
+			protected.GET("/products/:id/stock", getProductStockHandler(inventoryService))
+			protected.GET("/inventory/adjustments", getStockAdjustmentsHandler(inventoryService))
+
+			admin := protected.Group("/admin", middleware.AdminMiddleware())
+			{
+				admin.GET("/products", getAdminProductsHandler(catalogService))
+				admin.GET("/products/:id", getAdminProductHandler(catalogService))
+				admin.POST("/products/:id/restore", restoreProductHandler(catalogService))
+				admin.GET("/products/:id/audit", getProductAuditRecordsHandler(auditService))
+				admin.GET("/audit", getAuditRecordsHandler(auditService))
+				admin.POST("/attributes", createAttributeHandler(attributeService))
+				admin.DELETE("/attributes/:code", deleteAttributeHandler(attributeService))
+				admin.GET("/products/:id/translations", getTranslationsHandler(translationService))
+				admin.PUT("/products/:id/translations/:locale", setTranslationHandler(translationService))
+				admin.DELETE("/products/:id/translations/:locale", deleteTranslationHandler(translationService))
+				admin.GET("/translations/missing", getMissingTranslationsHandler(translationService))
+				admin.POST("/price-lists", createPriceListHandler(pricingService))
+				admin.PUT("/tax-rates", setTaxRateHandler(pricingService))
+				admin.DELETE("/tax-rates/:id", deleteTaxRateHandler(pricingService))
+				admin.POST("/warehouses", createWarehouseHandler(inventoryService))
+				admin.PATCH("/warehouses/:warehouseID", updateWarehouseHandler(inventoryService))
+				// Складские движения затрагивают товары всех продавцов, поэтому их проводят только администраторы
+				admin.POST("/inventory/adjustments", adjustStockHandler(inventoryService))
+				admin.POST("/inventory/transfers", transferStockHandler(inventoryService))
+				admin.GET("/replenishment", getReplenishmentReportHandler(replenishmentService))
+				admin.GET("/replenishment/export", exportReplenishmentReportHandler(replenishmentService))
+			}
 		}
 	}
 
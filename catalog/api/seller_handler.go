@@ -0,0 +1,47 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/service"
+	"net/http"
+	"time"
+)
+
+// @Summary Get seller storefront
+// @Description Get public products of a seller
+// @Tags sellers
+// @Produce json
+// @Param id path string true "Seller ID"
+// @Param Accept-Currency header string false "Currency to resolve prices in"
+// @Param currency query string false "Currency to resolve prices in (overrides Accept-Currency)"
+// @Param market query string false "Market of the price list"
+// @Param country query string false "Destination country (ISO 3166-1 alpha-2) to add tax-inclusive and tax-exclusive prices for"
+// @Param region query string false "Destination region within the country"
+// @Param Accept-Language header string false "Preferred languages of product text"
+// @Param locale query string false "Language of product text (overrides Accept-Language)"
+// @Success 200 {array} domain.Product
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "Seller not found"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/sellers/{id}/products [get]
+func getSellerProductsHandler(catalogService *service.CatalogService, pricingService *service.PricingService, translationService *service.TranslationService, cacheTTL time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		products, err := catalogService.GetSellerProducts(c.Param("id"))
+		if err != nil {
+			if errors.Is(err, domain.ErrSellerNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !applyPrices(c, pricingService, products) || !localize(c, translationService, products) {
+			return
+		}
+		c.Header("Vary", "Accept-Currency, Accept-Language")
+		setPublicCache(c, cacheTTL)
+		c.JSON(http.StatusOK, products)
+	}
+}
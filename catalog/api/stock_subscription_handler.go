@@ -0,0 +1,63 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/service"
+	"net/http"
+)
+
+func stockSubscriptionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrProductNotFound), errors.Is(err, domain.ErrSubscriptionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrProductInStock):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// @Summary Subscribe to back-in-stock notification
+// @Description Ask to be notified when an out-of-stock product is available again. Subscribers are listed in a product.back_in_stock event, after which the subscription is removed (requires authentication)
+// @Tags products
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Success 201 {object} domain.StockSubscription
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Product not found"
+// @Failure 409 {string} string "Product is in stock"
+// @Router /api/v1/products/{id}/notify-me [post]
+func subscribeStockHandler(stockSubscriptionService *service.StockSubscriptionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subscription, err := stockSubscriptionService.Subscribe(c.Param("id"), c.GetString("email"))
+		if err != nil {
+			c.JSON(stockSubscriptionErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, subscription)
+	}
+}
+
+// @Summary Cancel back-in-stock notification
+// @Description Remove the authenticated user's back-in-stock subscription to a product (requires authentication)
+// @Tags products
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Subscription not found"
+// @Router /api/v1/products/{id}/notify-me [delete]
+func unsubscribeStockHandler(stockSubscriptionService *service.StockSubscriptionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := stockSubscriptionService.Unsubscribe(c.Param("id"), c.GetString("email")); err != nil {
+			c.JSON(stockSubscriptionErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNoContent, gin.H{})
+	}
+}
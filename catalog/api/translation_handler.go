@@ -0,0 +1,158 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/yangirxd/store-app/catalog/api/dto"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/service"
+	"net/http"
+)
+
+func translationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrProductNotFound), errors.Is(err, domain.ErrTranslationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidTranslation):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// localize переводит товары на язык из query-параметра locale или заголовка Accept-Language
+func localize(c *gin.Context, translationService *service.TranslationService, products []*domain.Product) bool {
+	var preferred []string
+	if value := c.Query("locale"); value != "" {
+		locale, err := domain.ParseLocale(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return false
+		}
+		preferred = []string{locale}
+	} else {
+		preferred = domain.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+	}
+
+	locale, err := translationService.Localize(products, preferred)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	if locale != "" {
+		c.Header("Content-Language", locale)
+	}
+
+	return true
+}
+
+// @Summary Get product translations
+// @Description Get all translations of a product (requires admin rights)
+// @Tags translations
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Success 200 {array} domain.ProductTranslation
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Product not found"
+// @Router /api/v1/admin/products/{id}/translations [get]
+func getTranslationsHandler(translationService *service.TranslationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		translations, err := translationService.GetTranslations(c.Param("id"))
+		if err != nil {
+			status := translationErrorStatus(err)
+			if status == http.StatusInternalServerError {
+				status = http.StatusBadRequest
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, translations)
+	}
+}
+
+// @Summary Set a product translation
+// @Description Create or replace the name and description of a product in a locale (requires admin rights)
+// @Tags translations
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param locale path string true "Locale, e.g. en or en-gb"
+// @Param input body dto.SetTranslationRequest true "Translated text"
+// @Success 200 {object} domain.ProductTranslation
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Product not found"
+// @Router /api/v1/admin/products/{id}/translations/{locale} [put]
+func setTranslationHandler(translationService *service.TranslationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.SetTranslationRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		translation, err := translationService.SetTranslation(c.Param("id"), c.Param("locale"), req.Name, req.Description)
+		if err != nil {
+			status := translationErrorStatus(err)
+			if status == http.StatusInternalServerError {
+				status = http.StatusBadRequest
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, translation)
+	}
+}
+
+// @Summary Delete a product translation
+// @Description Delete the translation of a product in a locale (requires admin rights)
+// @Tags translations
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param locale path string true "Locale"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Translation not found"
+// @Router /api/v1/admin/products/{id}/translations/{locale} [delete]
+func deleteTranslationHandler(translationService *service.TranslationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := translationService.DeleteTranslation(c.Param("id"), c.Param("locale")); err != nil {
+			status := translationErrorStatus(err)
+			if status == http.StatusInternalServerError {
+				status = http.StatusBadRequest
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNoContent, gin.H{})
+	}
+}
+
+// @Summary Get products missing a translation
+// @Description Get products that have no translation in the given locale (requires admin rights)
+// @Tags translations
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param locale query string true "Locale"
+// @Success 200 {array} domain.Product
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/admin/translations/missing [get]
+func getMissingTranslationsHandler(translationService *service.TranslationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		products, err := translationService.GetMissingTranslations(c.Query("locale"))
+		if err != nil {
+			c.JSON(translationErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, products)
+	}
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/yangirxd/store-app/catalog/api/dto"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/service"
+	"net/http"
+	"strconv"
+)
+
+func warehouseErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrWarehouseNotFound), errors.Is(err, domain.ErrProductNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrWarehouseExists),
+		errors.Is(err, domain.ErrWarehouseNotEmpty),
+		errors.Is(err, domain.ErrInsufficientStock),
+		errors.Is(err, domain.ErrNoWarehouse):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidWarehouse), errors.Is(err, domain.ErrInvalidAdjustment):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// @Summary Create a warehouse
+// @Description Create a warehouse; stock is taken from warehouses in ascending priority (requires admin rights)
+// @Tags warehouses
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param input body dto.CreateWarehouseRequest true "Warehouse"
+// @Success 201 {object} domain.Warehouse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 409 {string} string "Warehouse already exists"
+// @Router /api/v1/admin/warehouses [post]
+func createWarehouseHandler(inventoryService *service.InventoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.CreateWarehouseRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		warehouse, err := inventoryService.CreateWarehouse(req.Code, req.Name, req.Priority)
+		if err != nil {
+			c.JSON(warehouseErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, warehouse)
+	}
+}
+
+// @Summary Update a warehouse
+// @Description Change name, priority or activity of a warehouse; only an empty warehouse can be deactivated (requires admin rights)
+// @Tags warehouses
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param warehouseID path string true "Warehouse ID"
+// @Param input body dto.UpdateWarehouseRequest true "Fields to change"
+// @Success 200 {object} domain.Warehouse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Warehouse not found"
+// @Failure 409 {string} string "Warehouse still holds stock"
+// @Router /api/v1/admin/warehouses/{warehouseID} [patch]
+func updateWarehouseHandler(inventoryService *service.InventoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.UpdateWarehouseRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		warehouse, err := inventoryService.UpdateWarehouse(c.Param("warehouseID"), req.Name, req.Priority, req.Active)
+		if err != nil {
+			status := warehouseErrorStatus(err)
+			if status == http.StatusInternalServerError {
+				status = http.StatusBadRequest
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, warehouse)
+	}
+}
+
+// @Summary Get warehouses
+// @Description Get all warehouses in priority order (requires authentication)
+// @Tags warehouses
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {array} domain.Warehouse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/warehouses [get]
+func getWarehousesHandler(inventoryService *service.InventoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		warehouses, err := inventoryService.GetWarehouses()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, warehouses)
+	}
+}
+
+// @Summary Get product stock by warehouse
+// @Description Get stock levels of a product per warehouse and the available-to-sell total (requires authentication)
+// @Tags warehouses
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Success 200 {object} domain.ProductStock
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Product not found"
+// @Router /api/v1/products/{id}/stock [get]
+func getProductStockHandler(inventoryService *service.InventoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stock, err := inventoryService.GetProductStock(c.Param("id"))
+		if err != nil {
+			status := warehouseErrorStatus(err)
+			if status == http.StatusInternalServerError {
+				status = http.StatusBadRequest
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, stock)
+	}
+}
+
+// @Summary Adjust stock
+// @Description Change stock of a product in a warehouse by delta with a reason code; the change is recorded in the adjustment ledger (requires admin rights)
+// @Tags warehouses
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param input body dto.StockAdjustmentRequest true "Adjustment"
+// @Success 201 {object} domain.StockAdjustment
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Product or warehouse not found"
+// @Failure 409 {string} string "Insufficient stock"
+// @Router /api/v1/admin/inventory/adjustments [post]
+func adjustStockHandler(inventoryService *service.InventoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.StockAdjustmentRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		adjustment, err := inventoryService.AdjustStock(req.ProductID, req.WarehouseID, req.Delta, req.Reason, req.Note, c.GetString("email"))
+		if err != nil {
+			c.JSON(warehouseErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, adjustment)
+	}
+}
+
+// @Summary Get stock adjustment ledger
+// @Description Get stock movements, newest first (requires authentication)
+// @Tags warehouses
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param productId query string false "Product ID"
+// @Param warehouseId query string false "Warehouse ID"
+// @Param reason query string false "Reason code"
+// @Param limit query int false "Maximum number of entries (default 100)"
+// @Success 200 {array} domain.StockAdjustment
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Router /api/v1/inventory/adjustments [get]
+func getStockAdjustmentsHandler(inventoryService *service.InventoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := 0
+		if value := c.Query("limit"); value != "" {
+			var err error
+			if limit, err = strconv.Atoi(value); err != nil || limit < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+				return
+			}
+		}
+		adjustments, err := inventoryService.GetStockAdjustments(c.Query("productId"), c.Query("warehouseId"), c.Query("reason"), limit)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, adjustments)
+	}
+}
+
+// @Summary Transfer stock between warehouses
+// @Description Move stock of a product from one warehouse to another; the total stock does not change (requires admin rights)
+// @Tags warehouses
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param input body dto.StockTransferRequest true "Transfer"
+// @Success 201 {array} domain.StockAdjustment
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Product or warehouse not found"
+// @Failure 409 {string} string "Insufficient stock"
+// @Router /api/v1/admin/inventory/transfers [post]
+func transferStockHandler(inventoryService *service.InventoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.StockTransferRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		adjustments, err := inventoryService.TransferStock(req.ProductID, req.FromWarehouseID, req.ToWarehouseID, req.Quantity, req.Note, c.GetString("email"))
+		if err != nil {
+			c.JSON(warehouseErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, adjustments)
+	}
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Basket — корзина пользователя в том виде, в котором ее отдает сервис basket
+type Basket struct {
+	ID        uuid.UUID
+	UserEmail string
+	CreatedAt time.Time
+	Items     []BasketItem
+}
+
+type BasketItem struct {
+	ID        uuid.UUID
+	ProductID uuid.UUID
+	Quantity  int
+	CreatedAt time.Time
+}
+
+type BasketClient interface {
+	// GetBasket возвращает корзину пользователя или nil, если он ее еще не создал
+	GetBasket(ctx context.Context, authorization string) (*Basket, error)
+}
+
+type HTTPBasketClient struct {
+	client httpClient
+}
+
+func NewHTTPBasketClient(baseURL string, timeout time.Duration) *HTTPBasketClient {
+	return &HTTPBasketClient{client: newHTTPClient(baseURL, timeout)}
+}
+
+func (c *HTTPBasketClient) GetBasket(ctx context.Context, authorization string) (*Basket, error) {
+	var basket Basket
+	if err := c.client.getJSON(ctx, "/api/v1/baskets", authorization, &basket); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &basket, nil
+}
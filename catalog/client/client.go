@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrNotFound = errors.New("resource not found")
+
+// httpClient — общий клиент соседних сервисов; запрос идет от имени пользователя с его заголовком Authorization
+type httpClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+func newHTTPClient(baseURL string, timeout time.Duration) httpClient {
+	return httpClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: timeout},
+	}
+}
+
+// getJSON выполняет GET и декодирует ответ в dst. Ответ 404 возвращается как ErrNotFound.
+func (c httpClient) getJSON(ctx context.Context, path, authorization string, dst interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("GET %s: unexpected status %s", path, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(dst)
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Order — заказ пользователя в том виде, в котором его отдает сервис orders
+type Order struct {
+	ID        uuid.UUID
+	UserEmail string
+	Total     float64
+	CreatedAt time.Time
+	Items     []OrderItem
+}
+
+type OrderItem struct {
+	ID        uuid.UUID
+	ProductID uuid.UUID
+	Quantity  int
+	Price     float64 // Цена на момент заказа
+}
+
+type OrdersClient interface {
+	GetOrders(ctx context.Context, authorization string) ([]Order, error)
+}
+
+type HTTPOrdersClient struct {
+	client httpClient
+}
+
+func NewHTTPOrdersClient(baseURL string, timeout time.Duration) *HTTPOrdersClient {
+	return &HTTPOrdersClient{client: newHTTPClient(baseURL, timeout)}
+}
+
+func (c *HTTPOrdersClient) GetOrders(ctx context.Context, authorization string) ([]Order, error) {
+	var orders []Order
+	if err := c.client.getJSON(ctx, "/api/v1/orders", authorization, &orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
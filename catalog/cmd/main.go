@@ -1,11 +1,20 @@
 package main
 
 import (
+	"context"
 	"github.com/yangirxd/store-app/catalog/api"
+	"github.com/yangirxd/store-app/catalog/client"
 	"github.com/yangirxd/store-app/catalog/db"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/graph"
+	"github.com/yangirxd/store-app/catalog/kafka"
 	"github.com/yangirxd/store-app/catalog/repository"
 	"github.com/yangirxd/store-app/catalog/service"
+	"github.com/yangirxd/store-app/catalog/storage"
 	"log"
+	"os"
+	"strconv"
+	"time"
 )
 
 func main() {
@@ -14,10 +23,215 @@ func main() {
 		log.Fatal("failed to initialize database: ", err)
 	}
 
+	baseCurrency := os.Getenv("BASE_CURRENCY")
+	if baseCurrency == "" {
+		baseCurrency = "RUB"
+	}
+	exchangeRates, err := domain.ParseExchangeRates(baseCurrency, os.Getenv("EXCHANGE_RATES"))
+	if err != nil {
+		log.Fatal("failed to parse exchange rates: ", err)
+	}
+
+	// Цены каталога и прайс-листов по умолчанию указываются с налогом
+	pricesIncludeTax := true
+	if value := os.Getenv("PRICES_INCLUDE_TAX"); value != "" {
+		pricesIncludeTax, err = strconv.ParseBool(value)
+		if err != nil {
+			log.Fatal("invalid PRICES_INCLUDE_TAX: ", err)
+		}
+	}
+
+	lowStockThreshold := 5
+	if value := os.Getenv("LOW_STOCK_THRESHOLD"); value != "" {
+		lowStockThreshold, err = strconv.Atoi(value)
+		if err != nil {
+			log.Fatal("invalid LOW_STOCK_THRESHOLD: ", err)
+		}
+	}
+
+	productCacheSize := 10000
+	if value := os.Getenv("PRODUCT_CACHE_SIZE"); value != "" {
+		productCacheSize, err = strconv.Atoi(value)
+		if err != nil {
+			log.Fatal("invalid PRODUCT_CACHE_SIZE: ", err)
+		}
+	}
+	productCacheTTL := 30 * time.Second
+	if value := os.Getenv("PRODUCT_CACHE_TTL"); value != "" {
+		productCacheTTL, err = time.ParseDuration(value)
+		if err != nil {
+			log.Fatal("invalid PRODUCT_CACHE_TTL: ", err)
+		}
+	}
+
+	// Язык, на котором заполнены названия и описания товаров; остальные языки — переводы
+	defaultLocale := "ru"
+	if value := os.Getenv("DEFAULT_LOCALE"); value != "" {
+		defaultLocale, err = domain.ParseLocale(value)
+		if err != nil {
+			log.Fatal("invalid DEFAULT_LOCALE: ", err)
+		}
+	}
+
+	// Характеристика, по которой похожие товары дополняются товарами той же категории
+	categoryAttribute := os.Getenv("CATEGORY_ATTRIBUTE")
+	if categoryAttribute == "" {
+		categoryAttribute = "category"
+	}
+
+	// Соседние сервисы, из которых GraphQL читает корзину и заказы
+	basketURL := os.Getenv("BASKET_URL")
+	if basketURL == "" {
+		basketURL = "http://basket:8083"
+	}
+	ordersURL := os.Getenv("ORDERS_URL")
+	if ordersURL == "" {
+		ordersURL = "http://orders:8084"
+	}
+	graphqlMaxDepth := 8
+	if value := os.Getenv("GRAPHQL_MAX_DEPTH"); value != "" {
+		graphqlMaxDepth, err = strconv.Atoi(value)
+		if err != nil {
+			log.Fatal("invalid GRAPHQL_MAX_DEPTH: ", err)
+		}
+	}
+	graphqlMaxComplexity := 1000
+	if value := os.Getenv("GRAPHQL_MAX_COMPLEXITY"); value != "" {
+		graphqlMaxComplexity, err = strconv.Atoi(value)
+		if err != nil {
+			log.Fatal("invalid GRAPHQL_MAX_COMPLEXITY: ", err)
+		}
+	}
+
+	mediaDir := os.Getenv("MEDIA_DIR")
+	if mediaDir == "" {
+		mediaDir = "media"
+	}
+	blobStore, err := storage.NewLocalBlobStore(mediaDir)
+	if err != nil {
+		log.Fatal("failed to initialize media storage: ", err)
+	}
+
+	// Настройка Kafka
+	brokers := []string{"kafka:9099"}
+	kafkaProducer := kafka.NewProducer(brokers)
+	defer kafkaProducer.Close()
+
+	// orders публикует orders.created, orders.cancelled и orders.delivered; orders.refunded пока никто не публикует,
+	// подписки на него ждут возвратов в orders
+	orderCreatedConsumer := kafka.NewConsumer(brokers, domain.TopicOrderCreated, "catalog-group")
+	orderCancelledConsumer := kafka.NewConsumer(brokers, domain.TopicOrderCancelled, "catalog-group")
+	orderRefundedConsumer := kafka.NewConsumer(brokers, domain.TopicOrderRefunded, "catalog-group")
+
+	// Отдельная группа: покупки для отзывов читаются из тех же топиков независимо от остатков
+	purchaseCreatedConsumer := kafka.NewConsumer(brokers, domain.TopicOrderCreated, "catalog-reviews-group")
+	purchaseDeliveredConsumer := kafka.NewConsumer(brokers, domain.TopicOrderDelivered, "catalog-reviews-group")
+	purchaseCancelledConsumer := kafka.NewConsumer(brokers, domain.TopicOrderCancelled, "catalog-reviews-group")
+	purchaseRefundedConsumer := kafka.NewConsumer(brokers, domain.TopicOrderRefunded, "catalog-reviews-group")
+
+	// Отдельная группа: совместные покупки считаются по тем же заказам независимо от остатков и отзывов
+	coPurchaseConsumer := kafka.NewConsumer(brokers, domain.TopicOrderCreated, "catalog-recommendations-group")
+
+	// Отдельная группа: продажи для отчета о дозаказе считаются по тем же заказам независимо от остатков
+	saleCreatedConsumer := kafka.NewConsumer(brokers, domain.TopicOrderCreated, "catalog-sales-group")
+	saleCancelledConsumer := kafka.NewConsumer(brokers, domain.TopicOrderCancelled, "catalog-sales-group")
+	saleRefundedConsumer := kafka.NewConsumer(brokers, domain.TopicOrderRefunded, "catalog-sales-group")
+
+	// Общая группа: о поступлении товара подписчиков уведомляет одна реплика
+	backInStockConsumer := kafka.NewConsumer(brokers, domain.TopicProductStockChanged, "catalog-notify-group")
+
+	// Группа уникальна для реплики: каждая реплика должна получить все события и сбросить свой кэш.
+	// История топика новой реплике не нужна, поэтому группа начинает с новых сообщений.
+	// Группы остановленных реплик Kafka удалит сама по истечении offsets.retention.minutes.
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Fatal("failed to get hostname: ", err)
+	}
+	var cacheConsumers []*kafka.Consumer
+	for _, topic := range service.ProductCacheTopics {
+		cacheConsumers = append(cacheConsumers, kafka.NewTailConsumer(brokers, topic, "catalog-cache-"+hostname))
+	}
+
 	productRepo := repository.NewPostgresProductRepository(catalogDB)
-	catalogService := service.NewCatalogService(productRepo)
+	if assigned, err := productRepo.AssignMissingSlugs(); err != nil {
+		log.Fatal("failed to assign product slugs: ", err)
+	} else if assigned > 0 {
+		log.Printf("Assigned slugs to %d products", assigned)
+	}
+	// Все сервисы, которые пишут товары, работают через кэш, чтобы их записи сразу сбрасывали его
+	cachedProductRepo := repository.NewCachedProductRepository(productRepo, productCacheSize, productCacheTTL)
+	priceListRepo := repository.NewPostgresPriceListRepository(catalogDB)
+	reservationRepo := repository.NewPostgresReservationRepository(catalogDB)
+	inventoryRepo := repository.NewPostgresInventoryRepository(catalogDB)
+	importJobRepo := repository.NewPostgresImportJobRepository(catalogDB)
+	priceHistoryRepo := repository.NewPostgresPriceHistoryRepository(catalogDB)
+	priceScheduleRepo := repository.NewPostgresPriceScheduleRepository(catalogDB)
+	reviewRepo := repository.NewPostgresReviewRepository(catalogDB)
+	purchaseRepo := repository.NewPostgresPurchaseRepository(catalogDB)
+	attributeRepo := repository.NewPostgresAttributeRepository(catalogDB)
+	mediaRepo := repository.NewPostgresMediaRepository(catalogDB)
+	warehouseRepo := repository.NewPostgresWarehouseRepository(catalogDB)
+	translationRepo := repository.NewPostgresTranslationRepository(catalogDB)
+	bundleRepo := repository.NewPostgresBundleRepository(catalogDB)
+	stockSubscriptionRepo := repository.NewPostgresStockSubscriptionRepository(catalogDB)
+	sellerRepo := repository.NewPostgresSellerRepository(catalogDB)
+	auditRepo := repository.NewPostgresAuditRepository(catalogDB)
+	taxRateRepo := repository.NewPostgresTaxRateRepository(catalogDB)
+	recommendationRepo := repository.NewPostgresRecommendationRepository(catalogDB)
+	replenishmentRepo := repository.NewPostgresReplenishmentRepository(catalogDB)
+	eventPublisher := service.NewEventPublisher(kafkaProducer, cachedProductRepo)
+	auditService := service.NewAuditService(auditRepo)
+	priceHistoryService := service.NewPriceHistoryService(cachedProductRepo, priceHistoryRepo, priceScheduleRepo, sellerRepo, auditService, eventPublisher)
+	catalogService := service.NewCatalogService(cachedProductRepo, bundleRepo, sellerRepo, priceHistoryService, auditService, eventPublisher)
+	pricingService := service.NewPricingService(priceListRepo, taxRateRepo, cachedProductRepo, sellerRepo, exchangeRates, pricesIncludeTax)
+	inventoryService := service.NewInventoryService(reservationRepo, inventoryRepo, warehouseRepo, bundleRepo, eventPublisher, lowStockThreshold)
+	importService := service.NewImportService(cachedProductRepo, sellerRepo, importJobRepo, priceHistoryService, auditService, eventPublisher)
+	reviewService := service.NewReviewService(reviewRepo, purchaseRepo, cachedProductRepo, eventPublisher)
+	attributeService := service.NewAttributeService(attributeRepo, cachedProductRepo, sellerRepo, auditService, eventPublisher)
+	mediaService := service.NewMediaService(mediaRepo, cachedProductRepo, sellerRepo, blobStore, eventPublisher)
+	translationService := service.NewTranslationService(translationRepo, cachedProductRepo, eventPublisher, defaultLocale)
+	stockSubscriptionService := service.NewStockSubscriptionService(stockSubscriptionRepo, bundleRepo, catalogService, eventPublisher)
+	recommendationService := service.NewRecommendationService(recommendationRepo, catalogService, categoryAttribute)
+	replenishmentService := service.NewReplenishmentService(replenishmentRepo, bundleRepo)
+	cacheInvalidator := service.NewCacheInvalidator(cachedProductRepo)
+	basketClient := client.NewHTTPBasketClient(basketURL, 5*time.Second)
+	ordersClient := client.NewHTTPOrdersClient(ordersURL, 5*time.Second)
+	graphServer, err := graph.NewServer(catalogService, pricingService, translationService, recommendationService, basketClient, ordersClient, graphqlMaxDepth, graphqlMaxComplexity)
+	if err != nil {
+		log.Fatal("failed to build GraphQL schema: ", err)
+	}
+
+	go orderCreatedConsumer.Consume(context.Background(), inventoryService.ProcessOrderCreatedEvent)
+	go orderCancelledConsumer.Consume(context.Background(), inventoryService.ProcessOrderCancelledEvent)
+	go orderRefundedConsumer.Consume(context.Background(), inventoryService.ProcessOrderRefundedEvent)
+
+	go purchaseCreatedConsumer.Consume(context.Background(), reviewService.ProcessOrderCreatedEvent)
+	go purchaseDeliveredConsumer.Consume(context.Background(), reviewService.ProcessOrderDeliveredEvent)
+	go purchaseCancelledConsumer.Consume(context.Background(), reviewService.ProcessOrderCancelledEvent)
+	go purchaseRefundedConsumer.Consume(context.Background(), reviewService.ProcessOrderRefundedEvent)
+
+	go coPurchaseConsumer.Consume(context.Background(), recommendationService.ProcessOrderCreatedEvent)
+
+	go saleCreatedConsumer.Consume(context.Background(), replenishmentService.ProcessOrderCreatedEvent)
+	go saleCancelledConsumer.Consume(context.Background(), replenishmentService.ProcessOrderCancelledEvent)
+	go saleRefundedConsumer.Consume(context.Background(), replenishmentService.ProcessOrderRefundedEvent)
+
+	go backInStockConsumer.Consume(context.Background(), stockSubscriptionService.ProcessStockChangedEvent)
+
+	for _, consumer := range cacheConsumers {
+		go consumer.Consume(context.Background(), cacheInvalidator.ProcessProductEvent)
+	}
+
+	// Фоновая отправка событий каталога
+	go eventPublisher.Run(context.Background())
+
+	// Освобождение просроченных резервов
+	go inventoryService.RunReservationSweeper(context.Background(), 30*time.Second)
+
+	// Запуск и завершение запланированных цен
+	go priceHistoryService.RunPriceScheduler(context.Background(), time.Minute)
 
-	r := api.SetupRouter(catalogService)
+	r := api.SetupRouter(catalogService, pricingService, priceHistoryService, inventoryService, importService, reviewService, attributeService, mediaService, translationService, stockSubscriptionService, auditService, recommendationService, replenishmentService, graphServer, productCacheTTL)
 
 	if err := r.Run(":8081"); err != nil {
 		log.Fatal("failed to start server:", err)
@@ -14,7 +14,8 @@ func InitCatalogDB() (*gorm.DB, error) {
 		log.Fatal("DATABASE_URL is not set")
 	}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// TranslateError превращает нарушения уникальных индексов в gorm.ErrDuplicatedKey
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal("failed to connect to database:", err)
 	}
@@ -23,9 +24,59 @@ func InitCatalogDB() (*gorm.DB, error) {
 		log.Fatal("failed to create uuid-ossp extension:", err)
 	}
 
-	if err := db.AutoMigrate(&domain.Product{}); err != nil {
+	if err := db.AutoMigrate(
+		&domain.Product{},
+		&domain.PriceList{},
+		&domain.PriceListEntry{},
+		&domain.Reservation{},
+		&domain.ReservationItem{},
+		&domain.ProcessedEvent{},
+		&domain.ImportJob{},
+		&domain.ImportRowError{},
+		&domain.PriceHistory{},
+		&domain.PriceSchedule{},
+		&domain.Review{},
+		&domain.Purchase{},
+		&domain.AttributeDefinition{},
+		&domain.ProductAttribute{},
+		&domain.ProductMedia{},
+		&domain.Warehouse{},
+		&domain.WarehouseStock{},
+		&domain.StockAdjustment{},
+		&domain.ProductSlug{},
+		&domain.ProductTranslation{},
+		&domain.BundleComponent{},
+		&domain.OrderComponent{},
+		&domain.StockSubscription{},
+		&domain.Seller{},
+		&domain.AuditRecord{},
+		&domain.TaxRate{},
+		&domain.CoPurchase{},
+		&domain.CoPurchaseOrder{},
+		&domain.ProductSale{},
+	); err != nil {
 		log.Fatal("failed to auto migrate user:", err)
 	}
 
+	if err := seedWarehouses(db); err != nil {
+		log.Fatal("failed to seed warehouses:", err)
+	}
+
 	return db, nil
 }
+
+// seedWarehouses создает основной склад, если складов еще нет, и переносит на него
+// остатки товаров, заведенных до появления складов
+func seedWarehouses(db *gorm.DB) error {
+	if err := db.Exec(`INSERT INTO warehouses (code, name, priority, active)
+		SELECT ?, ?, 0, true WHERE NOT EXISTS (SELECT 1 FROM warehouses)`,
+		domain.DefaultWarehouseCode, "Main warehouse").Error; err != nil {
+		return err
+	}
+
+	return db.Exec(`INSERT INTO warehouse_stocks (warehouse_id, product_id, quantity, updated_at)
+		SELECT w.id, p.id, p.stock, now()
+		FROM products p
+		CROSS JOIN (SELECT id FROM warehouses WHERE active ORDER BY priority, code LIMIT 1) w
+		WHERE p.stock > 0 AND NOT EXISTS (SELECT 1 FROM warehouse_stocks ws WHERE ws.product_id = p.id)`).Error
+}
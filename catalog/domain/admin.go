@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"os"
+	"strings"
+)
+
+// IsAdmin проверяет, входит ли email в список администраторов каталога из ADMIN_EMAILS
+func IsAdmin(email string) bool {
+	if email == "" {
+		return false
+	}
+
+	for _, admin := range strings.Split(os.Getenv("ADMIN_EMAILS"), ",") {
+		if strings.EqualFold(strings.TrimSpace(admin), email) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrAttributeNotFound = errors.New("attribute not found")
+	ErrAttributeExists   = errors.New("attribute with this code already exists")
+	ErrInvalidAttribute  = errors.New("invalid attribute")
+)
+
+type AttributeType string
+
+const (
+	AttributeText    AttributeType = "text"
+	AttributeNumber  AttributeType = "number"
+	AttributeEnum    AttributeType = "enum"
+	AttributeBoolean AttributeType = "boolean"
+)
+
+var attributeCodePattern = regexp.MustCompile(`^[a-z0-9_]{1,64}$`)
+
+// AttributeDefinition описывает характеристику товара (бренд, материал, диагональ)
+type AttributeDefinition struct {
+	ID         uuid.UUID     `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Code       string        `gorm:"not null;uniqueIndex"` // Ключ в фильтре attr[code]
+	Name       string        `gorm:"not null"`
+	Type       AttributeType `gorm:"not null"`
+	Options    []string      `gorm:"serializer:json"` // Допустимые значения для enum
+	Filterable bool          `gorm:"not null;default:true"`
+	CreatedAt  time.Time     `gorm:"default:current_timestamp"`
+}
+
+func NewAttributeDefinition(code, name string, attrType AttributeType, options []string, filterable bool) (*AttributeDefinition, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if !attributeCodePattern.MatchString(code) {
+		return nil, fmt.Errorf("%w: code must match %s", ErrInvalidAttribute, attributeCodePattern)
+	}
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidAttribute)
+	}
+
+	switch attrType {
+	case AttributeText, AttributeNumber, AttributeBoolean:
+		options = nil
+	case AttributeEnum:
+		if len(options) == 0 {
+			return nil, fmt.Errorf("%w: enum attribute requires options", ErrInvalidAttribute)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidAttribute, attrType)
+	}
+
+	return &AttributeDefinition{
+		ID:         uuid.New(),
+		Code:       code,
+		Name:       strings.TrimSpace(name),
+		Type:       attrType,
+		Options:    options,
+		Filterable: filterable,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// NormalizeValue проверяет значение по типу характеристики и приводит его к виду,
+// в котором оно хранится и сравнивается в фильтрах
+func (d *AttributeDefinition) NormalizeValue(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("%w: empty value for %q", ErrInvalidAttribute, d.Code)
+	}
+
+	switch d.Type {
+	case AttributeNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", fmt.Errorf("%w: %q expects a number, got %q", ErrInvalidAttribute, d.Code, value)
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+	case AttributeBoolean:
+		flag, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%w: %q expects a boolean, got %q", ErrInvalidAttribute, d.Code, value)
+		}
+		return strconv.FormatBool(flag), nil
+	case AttributeEnum:
+		for _, option := range d.Options {
+			if strings.EqualFold(option, value) {
+				return option, nil
+			}
+		}
+		return "", fmt.Errorf("%w: %q must be one of %v", ErrInvalidAttribute, d.Code, d.Options)
+	default:
+		return value, nil
+	}
+}
+
+// ProductAttribute — значение характеристики товара
+type ProductAttribute struct {
+	ProductID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Code      string    `gorm:"primaryKey;index:idx_product_attributes_code_value"`
+	Value     string    `gorm:"not null;index:idx_product_attributes_code_value"`
+}
+
+// AttributeFacet — число товаров с каждым значением характеристики
+type AttributeFacet struct {
+	Code   string
+	Name   string
+	Type   AttributeType
+	Values []FacetValue
+}
+
+type FacetValue struct {
+	Value string
+	Count int64
+}
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"reflect"
+	"time"
+)
+
+// SystemActor записывается в журнал изменений, которые каталог делает сам, например по расписанию цен
+const SystemActor = "system"
+
+type AuditAction string
+
+const (
+	AuditCreated  AuditAction = "created"
+	AuditUpdated  AuditAction = "updated"
+	AuditDeleted  AuditAction = "deleted"
+	AuditRestored AuditAction = "restored"
+)
+
+// FieldChange — значение поля до и после изменения; nil, если поля не было
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditRecord — запись журнала изменений товара: кто, когда и какие поля поменял
+type AuditRecord struct {
+	ID        uuid.UUID     `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	ProductID uuid.UUID     `gorm:"type:uuid;not null;index"`
+	Action    AuditAction   `gorm:"not null"`
+	Actor     string        `gorm:"not null;index"`
+	Changes   []FieldChange `gorm:"serializer:json"`
+	CreatedAt time.Time     `gorm:"not null;index"`
+}
+
+// NewAuditRecord сравнивает товар до и после изменения. При создании before равен nil,
+// при удалении — after. Возвращает nil, если ни одно поле не изменилось.
+func NewAuditRecord(action AuditAction, actor string, before, after *Product) *AuditRecord {
+	changes := diffProducts(before, after)
+	if len(changes) == 0 && action == AuditUpdated {
+		return nil
+	}
+
+	productID := uuid.Nil
+	if after != nil {
+		productID = after.ID
+	} else if before != nil {
+		productID = before.ID
+	}
+
+	return &AuditRecord{
+		ID:        uuid.New(),
+		ProductID: productID,
+		Action:    action,
+		Actor:     actor,
+		Changes:   changes,
+		CreatedAt: time.Now(),
+	}
+}
+
+// AuditFilter ограничивает выборку журнала; пустые поля не ограничивают
+type AuditFilter struct {
+	ProductID uuid.UUID
+	Actor     string
+	Action    AuditAction
+}
+
+func diffProducts(before, after *Product) []FieldChange {
+	beforeFields, afterFields := auditFields(before), auditFields(after)
+
+	var changes []FieldChange
+	for i, field := range auditFieldNames {
+		if !reflect.DeepEqual(beforeFields[i], afterFields[i]) {
+			changes = append(changes, FieldChange{Field: field, Before: beforeFields[i], After: afterFields[i]})
+		}
+	}
+
+	return changes
+}
+
+var auditFieldNames = []string{"sku", "name", "slug", "description", "price", "stock", "status", "taxClass", "reorderThreshold", "targetStock", "type", "sellerId", "attributes", "components"}
+
+// auditFields возвращает значения полей товара в порядке auditFieldNames. Характеристики
+// и состав набора сравниваются как словари, чтобы порядок строк не давал ложных изменений.
+func auditFields(p *Product) []interface{} {
+	if p == nil {
+		return make([]interface{}, len(auditFieldNames))
+	}
+
+	// Пустые значения остаются nil, иначе товар без характеристик отличался бы от товара с пустым словарем
+	var attributes, components, sellerID interface{}
+	if len(p.Attributes) > 0 {
+		values := make(map[string]string, len(p.Attributes))
+		for _, attribute := range p.Attributes {
+			values[attribute.Code] = attribute.Value
+		}
+		attributes = values
+	}
+	if len(p.Components) > 0 {
+		quantities := make(map[string]int, len(p.Components))
+		for _, component := range p.Components {
+			quantities[component.ProductID.String()] = component.Quantity
+		}
+		components = quantities
+	}
+	if p.SellerID != nil {
+		sellerID = p.SellerID.String()
+	}
+
+	return []interface{}{
+		p.SKU, p.Name, p.Slug, p.Description, p.Price, p.Stock, string(p.Status), string(p.TaxClass), p.ReorderThreshold, p.TargetStock, string(p.Type),
+		sellerID, attributes, components,
+	}
+}
@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidBundle = errors.New("invalid bundle")
+
+type ProductType string
+
+const (
+	ProductSimple ProductType = "simple"
+	ProductBundle ProductType = "bundle" // Набор: своего остатка нет, он собирается из компонентов
+)
+
+// BundleComponent — товар, входящий в набор, и его количество в одном наборе
+type BundleComponent struct {
+	BundleID  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ProductID uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	Quantity  int       `gorm:"not null"`
+}
+
+// NewBundleComponents проверяет состав набора: компоненты не повторяются,
+// количество положительное, набор не входит сам в себя
+func NewBundleComponents(bundleID uuid.UUID, components []BundleComponent) ([]BundleComponent, error) {
+	seen := make(map[uuid.UUID]bool, len(components))
+	result := make([]BundleComponent, 0, len(components))
+	for _, component := range components {
+		if component.ProductID == bundleID {
+			return nil, fmt.Errorf("%w: bundle cannot contain itself", ErrInvalidBundle)
+		}
+		if seen[component.ProductID] {
+			return nil, fmt.Errorf("%w: component %s is listed twice", ErrInvalidBundle, component.ProductID)
+		}
+		if component.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity of %s must be positive", ErrInvalidBundle, component.ProductID)
+		}
+		seen[component.ProductID] = true
+		result = append(result, BundleComponent{BundleID: bundleID, ProductID: component.ProductID, Quantity: component.Quantity})
+	}
+
+	return result, nil
+}
+
+func (p *Product) IsBundle() bool {
+	return p.Type == ProductBundle
+}
+
+// ExpandBundles заменяет наборы их компонентами: набор × n превращается в компонент × n·quantity.
+// Изменения одного товара объединяются, порядок первых упоминаний сохраняется.
+func ExpandBundles(deltas []StockDelta, components map[uuid.UUID][]BundleComponent) []StockDelta {
+	expanded := make([]StockDelta, 0, len(deltas))
+	index := make(map[uuid.UUID]int, len(deltas))
+	add := func(productID uuid.UUID, delta int) {
+		if i, ok := index[productID]; ok {
+			expanded[i].Delta += delta
+			return
+		}
+		index[productID] = len(expanded)
+		expanded = append(expanded, StockDelta{ProductID: productID, Delta: delta})
+	}
+
+	for _, delta := range deltas {
+		bundle, ok := components[delta.ProductID]
+		if !ok {
+			add(delta.ProductID, delta.Delta)
+			continue
+		}
+		for _, component := range bundle {
+			add(component.ProductID, delta.Delta*component.Quantity)
+		}
+	}
+
+	return expanded
+}
+
+// OrderComponent — снимок состава позиции заказа на момент его создания: сколько единиц
+// компонента списано на одну заказанную единицу. Отмена и возврат возвращают остатки по снимку,
+// а не по текущему составу набора, который мог измениться после оформления заказа.
+// Обычный товар записывается сам себе компонентом с количеством 1.
+type OrderComponent struct {
+	OrderID     uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ProductID   uuid.UUID `gorm:"type:uuid;primaryKey"` // Позиция заказа: набор или обычный товар
+	ComponentID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Quantity    int       `gorm:"not null"` // Единиц компонента на одну единицу позиции
+	Ordered     int       `gorm:"not null"` // Заказано единиц позиции
+}
+
+// NewOrderComponents раскладывает позиции заказа на компоненты по составу наборов components
+func NewOrderComponents(order *OrderEvent, components map[uuid.UUID][]BundleComponent) []OrderComponent {
+	result := make([]OrderComponent, 0, len(order.Items))
+	index := make(map[[2]uuid.UUID]int, len(order.Items))
+	add := func(productID, componentID uuid.UUID, quantity, ordered int) {
+		key := [2]uuid.UUID{productID, componentID}
+		if i, ok := index[key]; ok {
+			// Одна позиция повторяется в заказе: состав тот же, заказанное количество складывается
+			result[i].Ordered += ordered
+			return
+		}
+		index[key] = len(result)
+		result = append(result, OrderComponent{
+			OrderID:     order.ID,
+			ProductID:   productID,
+			ComponentID: componentID,
+			Quantity:    quantity,
+			Ordered:     ordered,
+		})
+	}
+
+	for _, item := range order.Items {
+		if item.Quantity <= 0 {
+			continue
+		}
+		bundle, ok := components[item.ProductID]
+		if !ok {
+			add(item.ProductID, item.ProductID, 1, item.Quantity)
+			continue
+		}
+		for _, component := range bundle {
+			add(item.ProductID, component.ProductID, component.Quantity, item.Quantity)
+		}
+	}
+
+	return result
+}
+
+// OrderComponentDeltas возвращает движение остатков компонентов по снимку заказа, умноженное на sign.
+// Без items учитывается весь заказ, иначе только перечисленные позиции, но не больше заказанного.
+// Изменения одного компонента объединяются, порядок первых упоминаний сохраняется.
+func OrderComponentDeltas(snapshot []OrderComponent, items []OrderEventItem, sign int) []StockDelta {
+	var quantities map[uuid.UUID]int
+	if items != nil {
+		quantities = make(map[uuid.UUID]int, len(items))
+		for _, item := range items {
+			if item.Quantity > 0 {
+				quantities[item.ProductID] += item.Quantity
+			}
+		}
+	}
+
+	deltas := make([]StockDelta, 0, len(snapshot))
+	index := make(map[uuid.UUID]int, len(snapshot))
+	for _, line := range snapshot {
+		units := line.Ordered
+		if quantities != nil {
+			units = min(quantities[line.ProductID], line.Ordered)
+		}
+		if units <= 0 {
+			continue
+		}
+
+		delta := sign * units * line.Quantity
+		if i, ok := index[line.ComponentID]; ok {
+			deltas[i].Delta += delta
+			continue
+		}
+		index[line.ComponentID] = len(deltas)
+		deltas = append(deltas, StockDelta{ProductID: line.ComponentID, Delta: delta})
+	}
+
+	return deltas
+}
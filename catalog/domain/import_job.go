@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+var (
+	ErrImportJobNotFound  = errors.New("import job not found")
+	ErrImportJobForbidden = errors.New("import job belongs to another user")
+)
+
+type ImportFormat string
+
+const (
+	FormatCSV    ImportFormat = "csv"
+	FormatNDJSON ImportFormat = "ndjson"
+)
+
+func ParseImportFormat(format string) (ImportFormat, error) {
+	switch ImportFormat(strings.ToLower(format)) {
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatNDJSON:
+		return FormatNDJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+type ImportJobStatus string
+
+const (
+	ImportPending   ImportJobStatus = "pending"
+	ImportRunning   ImportJobStatus = "running"
+	ImportCompleted ImportJobStatus = "completed"
+	ImportFailed    ImportJobStatus = "failed"
+)
+
+// MaxImportErrors ограничивает число сохраняемых ошибок строк, чтобы битый файл не раздувал отчет
+const MaxImportErrors = 1000
+
+// ImportJob отслеживает фоновый импорт товаров
+type ImportJob struct {
+	ID          uuid.UUID       `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Format      ImportFormat    `gorm:"not null"`
+	DryRun      bool            `gorm:"not null;default:false"`
+	Status      ImportJobStatus `gorm:"not null;default:'pending'"`
+	CreatedBy   string
+	TotalRows   int       `gorm:"not null;default:0"`
+	CreatedRows int       `gorm:"not null;default:0"`
+	UpdatedRows int       `gorm:"not null;default:0"`
+	FailedRows  int       `gorm:"not null;default:0"`
+	Message     string    // Причина ошибки всего задания
+	CreatedAt   time.Time `gorm:"default:current_timestamp"`
+	FinishedAt  *time.Time
+	Errors      []ImportRowError `gorm:"foreignKey:JobID"`
+}
+
+// CanView сообщает, может ли actor видеть задание: в отчете артикулы и ошибки продавца,
+// поэтому оно доступно только запустившему его пользователю и администраторам
+func (j *ImportJob) CanView(actor Actor) bool {
+	return actor.IsAdmin || NormalizeEmail(j.CreatedBy) == NormalizeEmail(actor.Email)
+}
+
+// ImportRowError описывает строку, которую не удалось импортировать
+type ImportRowError struct {
+	ID      uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	JobID   uuid.UUID `gorm:"type:uuid;not null;index"`
+	Row     int       `gorm:"not null"`
+	SKU     string
+	Message string `gorm:"not null"`
+}
+
+func NewImportJob(format ImportFormat, dryRun bool, createdBy string) *ImportJob {
+	return &ImportJob{
+		ID:        uuid.New(),
+		Format:    format,
+		DryRun:    dryRun,
+		Status:    ImportPending,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+}
+
+// AddError учитывает ошибку строки; в отчет попадают первые MaxImportErrors ошибок
+func (j *ImportJob) AddError(row int, sku, message string) {
+	j.FailedRows++
+	if len(j.Errors) < MaxImportErrors {
+		j.Errors = append(j.Errors, ImportRowError{
+			ID:      uuid.New(),
+			JobID:   j.ID,
+			Row:     row,
+			SKU:     sku,
+			Message: message,
+		})
+	}
+}
+
+func (j *ImportJob) Finish(status ImportJobStatus, message string) {
+	now := time.Now()
+	j.Status = status
+	j.Message = message
+	j.FinishedAt = &now
+}
+
+// ProductRow — строка файла импорта и экспорта товаров
+type ProductRow struct {
+	SKU         string  `json:"sku"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price"`
+	Stock       int     `json:"stock"`
+	Status      string  `json:"status,omitempty"`
+	// Поля, которые есть в строке: колонки CSV или ключи NDJSON. При обновлении по SKU
+	// отсутствующие поля не меняются. nil означает, что заданы все поля.
+	Fields map[string]bool `json:"-"`
+}
+
+// Has сообщает, задано ли поле в строке файла
+func (r *ProductRow) Has(field string) bool {
+	return r.Fields == nil || r.Fields[field]
+}
+
+// ToProduct проверяет строку теми же правилами, что и NewProduct
+func (r *ProductRow) ToProduct() (*Product, error) {
+	sku := strings.TrimSpace(r.SKU)
+	if sku == "" {
+		return nil, fmt.Errorf("%w: sku is required for import", ErrInvalidProduct)
+	}
+
+	product, err := NewProduct(r.Name, r.Description, r.Price, r.Stock)
+	if err != nil {
+		return nil, err
+	}
+	product.SKU = sku
+	if r.Status != "" {
+		if product.Status, err = ParseProductStatus(r.Status); err != nil {
+			return nil, err
+		}
+	}
+
+	return product, nil
+}
+
+func NewProductRow(product *Product) ProductRow {
+	return ProductRow{
+		SKU:         product.SKU,
+		Name:        product.Name,
+		Description: product.Description,
+		Price:       product.Price,
+		Stock:       product.Stock,
+		Status:      string(product.Status),
+	}
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	TopicOrderCreated   = "orders.created"
+	TopicOrderCancelled = "orders.cancelled"
+	TopicOrderRefunded  = "orders.refunded"
+	TopicOrderDelivered = "orders.delivered"
+
+	TopicInventoryLow        = "inventory.low"
+	TopicInventoryOutOfStock = "inventory.out_of_stock"
+)
+
+var ErrEventAlreadyProcessed = errors.New("event already processed")
+
+// OrderEvent — payload событий orders.* (сериализованный заказ из сервиса orders)
+type OrderEvent struct {
+	EventID   string
+	ID        uuid.UUID
+	UserEmail string
+	Items     []OrderEventItem
+}
+
+type OrderEventItem struct {
+	ProductID uuid.UUID
+	Quantity  int
+}
+
+// ProcessedEvent хранит ID обработанных событий, чтобы повторная доставка не меняла остатки
+type ProcessedEvent struct {
+	EventID     string    `gorm:"primaryKey"`
+	Topic       string    `gorm:"not null"`
+	ProcessedAt time.Time `gorm:"default:current_timestamp"`
+}
+
+// StockEvent описывает изменение остатков, вызванное внешним событием.
+// Если ReservationReferenceID указан и по нему есть резерв, остатки уже списаны и резерв только подтверждается.
+type StockEvent struct {
+	EventID                string
+	Topic                  string
+	ReservationReferenceID string
+	Deltas                 []StockDelta
+}
+
+type StockDelta struct {
+	ProductID uuid.UUID
+	Delta     int
+}
+
+type StockChange struct {
+	ProductID uuid.UUID
+	Before    int
+	After     int
+}
+
+// CrossedLowStock сообщает, опустился ли остаток до порога, оставаясь положительным
+func (c StockChange) CrossedLowStock(threshold int) bool {
+	return c.Before > threshold && c.After <= threshold && c.After > 0
+}
+
+func (c StockChange) CrossedOutOfStock() bool {
+	return c.Before > 0 && c.After <= 0
+}
+
+// InventoryAlertEvent публикуется в inventory.low и inventory.out_of_stock
+type InventoryAlertEvent struct {
+	ProductID     uuid.UUID `json:"productId"`
+	Stock         int       `json:"stock"`
+	PreviousStock int       `json:"previousStock"`
+	Threshold     int       `json:"threshold"`
+	OccurredAt    time.Time `json:"occurredAt"`
+}
+
+func NewStockEvent(topic string, order *OrderEvent, sign int) *StockEvent {
+	eventID := order.EventID
+	if eventID == "" {
+		eventID = topic + ":" + order.ID.String()
+	}
+
+	event := &StockEvent{
+		EventID: eventID,
+		Topic:   topic,
+	}
+	for _, item := range order.Items {
+		if item.Quantity <= 0 {
+			continue
+		}
+		event.Deltas = append(event.Deltas, StockDelta{
+			ProductID: item.ProductID,
+			Delta:     sign * item.Quantity,
+		})
+	}
+
+	return event
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMediaNotFound     = errors.New("media not found")
+	ErrUnsupportedMedia  = errors.New("unsupported media type")
+	ErrInvalidMediaOrder = errors.New("invalid media order")
+)
+
+// defaultMediaBaseURL — путь раздачи файлов через traefik (префикс /catalog снимается прокси)
+const defaultMediaBaseURL = "/catalog/api/v1/media"
+
+// ProductMedia — изображение товара. Файлы лежат в BlobStore, в базе только ключи.
+type ProductMedia struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	ProductID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	Position     int       `gorm:"not null;default:0"` // Порядок показа, 0 — главное изображение
+	Key          string    `gorm:"not null"`
+	ThumbnailKey string    `gorm:"not null"`
+	ContentType  string    `gorm:"not null"`
+	Size         int64     `gorm:"not null"`
+	Width        int       `gorm:"not null"`
+	Height       int       `gorm:"not null"`
+	CreatedAt    time.Time `gorm:"default:current_timestamp"`
+	URL          string    `gorm:"-"`
+	ThumbnailURL string    `gorm:"-"`
+}
+
+func NewProductMedia(productID uuid.UUID, extension, contentType string, size int64, width, height int) *ProductMedia {
+	id := uuid.New()
+	prefix := "products/" + productID.String() + "/" + id.String()
+
+	media := &ProductMedia{
+		ID:           id,
+		ProductID:    productID,
+		Key:          prefix + extension,
+		ThumbnailKey: prefix + "_thumb.jpg",
+		ContentType:  contentType,
+		Size:         size,
+		Width:        width,
+		Height:       height,
+		CreatedAt:    time.Now(),
+	}
+	media.fillURLs()
+
+	return media
+}
+
+func (m *ProductMedia) AfterFind(tx *gorm.DB) error {
+	m.fillURLs()
+	return nil
+}
+
+// fillURLs строит публичные ссылки по ключам; базовый адрес задается MEDIA_BASE_URL
+func (m *ProductMedia) fillURLs() {
+	m.URL = MediaURL(m.Key)
+	m.ThumbnailURL = MediaURL(m.ThumbnailKey)
+}
+
+func MediaURL(key string) string {
+	baseURL := os.Getenv("MEDIA_BASE_URL")
+	if baseURL == "" {
+		baseURL = defaultMediaBaseURL
+	}
+
+	return strings.TrimRight(baseURL, "/") + "/" + key
+}
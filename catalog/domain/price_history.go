@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+var (
+	ErrScheduleNotFound = errors.New("price schedule not found")
+	ErrScheduleOverlap  = errors.New("price schedule overlaps an existing one")
+	ErrInvalidSchedule  = errors.New("invalid price schedule")
+)
+
+type PriceChangeReason string
+
+const (
+	PriceChangeManual        PriceChangeReason = "manual"
+	PriceChangeImport        PriceChangeReason = "import"
+	PriceChangeScheduleStart PriceChangeReason = "schedule_start"
+	PriceChangeScheduleEnd   PriceChangeReason = "schedule_end"
+)
+
+// PriceHistory — неизменяемая запись об изменении базовой цены товара
+type PriceHistory struct {
+	ID         uuid.UUID         `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	ProductID  uuid.UUID         `gorm:"type:uuid;not null;index"`
+	OldPrice   float64           `gorm:"not null;type:numeric"`
+	NewPrice   float64           `gorm:"not null;type:numeric"`
+	Reason     PriceChangeReason `gorm:"not null"`
+	ScheduleID *uuid.UUID        `gorm:"type:uuid"`
+	ChangedAt  time.Time         `gorm:"not null;index"`
+}
+
+func NewPriceHistory(productID uuid.UUID, oldPrice, newPrice float64, reason PriceChangeReason) *PriceHistory {
+	return &PriceHistory{
+		ID:        uuid.New(),
+		ProductID: productID,
+		OldPrice:  oldPrice,
+		NewPrice:  newPrice,
+		Reason:    reason,
+		ChangedAt: time.Now(),
+	}
+}
+
+type PriceScheduleStatus string
+
+const (
+	SchedulePending   PriceScheduleStatus = "pending"
+	ScheduleActive    PriceScheduleStatus = "active"
+	ScheduleCompleted PriceScheduleStatus = "completed"
+	ScheduleCancelled PriceScheduleStatus = "cancelled"
+)
+
+// PriceSchedule — запланированная цена (например, распродажа) с началом и необязательным концом.
+// При окончании товару возвращается цена, действовавшая до начала.
+type PriceSchedule struct {
+	ID            uuid.UUID           `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	ProductID     uuid.UUID           `gorm:"type:uuid;not null;index"`
+	Price         float64             `gorm:"not null;type:numeric"`
+	StartsAt      time.Time           `gorm:"not null;index"`
+	EndsAt        *time.Time          `gorm:"index"`
+	Status        PriceScheduleStatus `gorm:"not null;default:'pending';index"`
+	PreviousPrice *float64            `gorm:"type:numeric"`
+	CreatedAt     time.Time           `gorm:"default:current_timestamp"`
+}
+
+func NewPriceSchedule(productID uuid.UUID, price float64, startsAt time.Time, endsAt *time.Time) (*PriceSchedule, error) {
+	if price < 0 {
+		return nil, fmt.Errorf("%w: price cannot be negative", ErrInvalidSchedule)
+	}
+	if startsAt.IsZero() {
+		return nil, fmt.Errorf("%w: start time is required", ErrInvalidSchedule)
+	}
+	if endsAt != nil && !endsAt.After(startsAt) {
+		return nil, fmt.Errorf("%w: end time must be after start time", ErrInvalidSchedule)
+	}
+
+	return &PriceSchedule{
+		ID:        uuid.New(),
+		ProductID: productID,
+		Price:     price,
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		Status:    SchedulePending,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// Overlaps сообщает, пересекаются ли периоды действия двух расписаний
+func (s *PriceSchedule) Overlaps(other *PriceSchedule) bool {
+	startsBeforeOtherEnds := other.EndsAt == nil || s.StartsAt.Before(*other.EndsAt)
+	endsAfterOtherStarts := s.EndsAt == nil || s.EndsAt.After(other.StartsAt)
+	return startsBeforeOtherEnds && endsAfterOtherStarts
+}
+
+// IsOver сообщает, что период расписания закончился к моменту now
+func (s *PriceSchedule) IsOver(now time.Time) bool {
+	return s.EndsAt != nil && !now.Before(*s.EndsAt)
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrPriceListNotFound   = errors.New("price list not found")
+	ErrPriceListExists     = errors.New("price list for this currency and market already exists")
+	ErrInvalidPriceList    = errors.New("invalid price list")
+)
+
+// PriceList задает цены в конкретной валюте для рынка.
+// Список с пустым Market или IsDefault используется, когда рынок не указан.
+type PriceList struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Currency  string    `gorm:"not null;uniqueIndex:idx_price_list_currency_market"`
+	Market    string    `gorm:"not null;default:'';uniqueIndex:idx_price_list_currency_market"`
+	IsDefault bool      `gorm:"not null;default:false"`
+	CreatedAt time.Time `gorm:"default:current_timestamp"`
+}
+
+// PriceListEntry переопределяет цену товара в прайс-листе
+type PriceListEntry struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	PriceListID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_price_list_entry_product"`
+	ProductID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_price_list_entry_product"`
+	Price       float64   `gorm:"not null;type:numeric"`
+	CreatedAt   time.Time `gorm:"default:current_timestamp"`
+}
+
+func NewPriceList(currency, market string, isDefault bool) (*PriceList, error) {
+	currency = NormalizeCurrency(currency)
+	if len(currency) != 3 {
+		return nil, fmt.Errorf("%w: currency must be a 3-letter ISO code", ErrInvalidPriceList)
+	}
+
+	return &PriceList{
+		ID:        uuid.New(),
+		Currency:  currency,
+		Market:    strings.ToUpper(strings.TrimSpace(market)),
+		IsDefault: isDefault,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+func NewPriceListEntry(priceListID, productID uuid.UUID, price float64) (*PriceListEntry, error) {
+	if price < 0 {
+		return nil, fmt.Errorf("%w: price cannot be negative", ErrInvalidPriceList)
+	}
+
+	return &PriceListEntry{
+		ID:          uuid.New(),
+		PriceListID: priceListID,
+		ProductID:   productID,
+		Price:       price,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+func NormalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
+
+// ExchangeRates хранит курсы валют относительно базовой валюты каталога:
+// 1 единица базовой валюты = Rates[currency] единиц currency.
+type ExchangeRates struct {
+	Base  string
+	Rates map[string]float64
+}
+
+// ParseExchangeRates разбирает таблицу курсов вида "USD=0.011,EUR=0.010"
+func ParseExchangeRates(base, table string) (*ExchangeRates, error) {
+	base = NormalizeCurrency(base)
+	rates := &ExchangeRates{
+		Base:  base,
+		Rates: map[string]float64{base: 1},
+	}
+
+	for _, pair := range strings.Split(table, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		currency, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid exchange rate %q", pair)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid exchange rate %q", pair)
+		}
+		rates.Rates[NormalizeCurrency(currency)] = rate
+	}
+
+	return rates, nil
+}
+
+// Convert переводит сумму из базовой валюты в указанную
+func (r *ExchangeRates) Convert(amount float64, currency string) (float64, error) {
+	rate, ok := r.Rates[NormalizeCurrency(currency)]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+	}
+
+	return math.Round(amount*rate*100) / 100, nil
+}
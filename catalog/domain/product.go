@@ -1,34 +1,135 @@
 package domain
 
 import (
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 	"time"
 )
 
+var (
+	ErrProductNotFound = errors.New("product not found")
+	ErrVersionConflict = errors.New("product was modified concurrently")
+	ErrInvalidProduct  = errors.New("invalid product")
+	ErrInvalidID       = errors.New("invalid UUID")
+)
+
+type ProductStatus string
+
+const (
+	ProductDraft    ProductStatus = "draft"
+	ProductActive   ProductStatus = "active"
+	ProductArchived ProductStatus = "archived"
+)
+
+func ParseProductStatus(status string) (ProductStatus, error) {
+	switch ProductStatus(status) {
+	case ProductDraft, ProductActive, ProductArchived:
+		return ProductStatus(status), nil
+	default:
+		return "", fmt.Errorf("%w: unknown status %q", ErrInvalidProduct, status)
+	}
+}
+
 type Product struct {
 	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	SKU         string    `gorm:"index:idx_products_sku,unique,where:sku <> ''"`   // Артикул, ключ для импорта
+	Slug        string    `gorm:"index:idx_products_slug,unique,where:slug <> ''"` // Часть URL витрины, меняется вместе с названием
 	Name        string    `gorm:"not null"`
 	Description string
-	Price       float64   `gorm:"not null;type:numeric"`
-	Stock       int       `gorm:"not null;default:0"`
-	CreatedAt   time.Time `gorm:"default:current_timestamp"`
+	Price       float64            `gorm:"not null;type:numeric"`
+	Stock       int                `gorm:"not null;default:0"`
+	Status      ProductStatus      `gorm:"not null;default:'active';index"`
+	Type        ProductType        `gorm:"not null;default:'simple'"`   // У набора Stock вычисляется из остатков компонентов
+	SellerID    *uuid.UUID         `gorm:"type:uuid;index"`             // Продавец, создавший товар; пуст у товаров, созданных до маркетплейса
+	TaxClass    TaxClass           `gorm:"not null;default:'standard'"` // По классу выбирается ставка налога страны доставки
+	Version     int                `gorm:"not null;default:1"`          // Увеличивается при каждом изменении, используется в ETag
+	CreatedAt   time.Time          `gorm:"default:current_timestamp"`
+	UpdatedAt   time.Time          `gorm:"default:current_timestamp"`  // Время последнего изменения
+	DeletedAt   gorm.DeletedAt     `gorm:"index" swaggertype:"string"` // Мягкое удаление: товар скрыт, но ссылки из заказов остаются валидными
+	Currency    string             `gorm:"-"`                          // Валюта, в которой рассчитана Price для ответа
+	Locale      string             `gorm:"-"`                          // Язык, на котором отданы Name и Description
+	Attributes  []ProductAttribute `gorm:"foreignKey:ProductID"`
+	Media       []ProductMedia     `gorm:"foreignKey:ProductID"` // Изображения в порядке Position
+	Components  []BundleComponent  `gorm:"foreignKey:BundleID"`  // Состав набора, пуст у обычного товара
+	// Агрегаты отзывов, пересчитываются при каждом изменении отзыва
+	RatingAverage float64 `gorm:"not null;default:0;type:numeric(3,2)"`
+	RatingCount   int     `gorm:"not null;default:0"`
+	// Остаток, при котором товар пора дозаказать (0 — не отслеживается), и уровень, до которого его дозаказывают
+	ReorderThreshold int `gorm:"not null;default:0"`
+	TargetStock      int `gorm:"not null;default:0"`
+	// Цены с налогом и без него для страны доставки из запроса; пусты, если страна не указана
+	TaxRate           *float64 `gorm:"-"` // Проценты
+	PriceIncludingTax *float64 `gorm:"-"`
+	PriceExcludingTax *float64 `gorm:"-"`
 }
 
 func NewProduct(name, description string, price float64, stock int) (*Product, error) {
-	if name == "" {
-		return nil, fmt.Errorf("name cannot be empty")
-	}
-	if price < 0 {
-		return nil, fmt.Errorf("price cannot be negative")
+	if err := validateProduct(name, price, stock); err != nil {
+		return nil, err
 	}
 
+	now := time.Now()
 	return &Product{
 		ID:          uuid.New(),
 		Name:        name,
 		Description: description,
 		Price:       price,
 		Stock:       stock,
-		CreatedAt:   time.Now(),
+		Status:      ProductActive,
+		Type:        ProductSimple,
+		TaxClass:    TaxStandard,
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
 	}, nil
 }
+
+// IsPublic сообщает, виден ли товар в публичном каталоге
+func (p *Product) IsPublic() bool {
+	return p.Status == ProductActive && !p.DeletedAt.Valid
+}
+
+// ProductFilter ограничивает выборку товаров
+type ProductFilter struct {
+	Statuses       []ProductStatus
+	IncludeDeleted bool
+	SellerID       uuid.UUID // uuid.Nil — товары всех продавцов
+	// Attributes — код характеристики и допустимые значения:
+	// значения одного кода объединяются через ИЛИ, разные коды — через И
+	Attributes map[string][]string
+}
+
+// WithoutAttribute возвращает копию фильтра без условия по характеристике code.
+// Нужна для подсчета фасетов: значения выбранной характеристики не должны сужать сами себя.
+func (f ProductFilter) WithoutAttribute(code string) ProductFilter {
+	attributes := make(map[string][]string, len(f.Attributes))
+	for key, values := range f.Attributes {
+		if key != code {
+			attributes[key] = values
+		}
+	}
+	f.Attributes = attributes
+	return f
+}
+
+// PublicProductFilter — выборка для публичного каталога
+func PublicProductFilter() ProductFilter {
+	return ProductFilter{Statuses: []ProductStatus{ProductActive}}
+}
+
+// validateProduct содержит инварианты товара, общие для создания и изменения
+func validateProduct(name string, price float64, stock int) error {
+	if name == "" {
+		return fmt.Errorf("%w: name cannot be empty", ErrInvalidProduct)
+	}
+	if price < 0 {
+		return fmt.Errorf("%w: price cannot be negative", ErrInvalidProduct)
+	}
+	if stock < 0 {
+		return fmt.Errorf("%w: stock cannot be negative", ErrInvalidProduct)
+	}
+
+	return nil
+}
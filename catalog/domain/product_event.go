@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	TopicProductCreated      = "product.created"
+	TopicProductUpdated      = "product.updated"
+	TopicProductDeleted      = "product.deleted"
+	TopicProductStockChanged = "product.stock_changed"
+)
+
+// ProductEvent публикуется при изменении каталога; ключ сообщения — ID товара
+type ProductEvent struct {
+	EventID       uuid.UUID `json:"eventId"`
+	Type          string    `json:"type"`
+	ProductID     uuid.UUID `json:"productId"`
+	OccurredAt    time.Time `json:"occurredAt"`
+	Product       *Product  `json:"product,omitempty"`
+	ChangedFields []string  `json:"changedFields,omitempty"`
+	OldPrice      *float64  `json:"oldPrice,omitempty"`
+	NewPrice      *float64  `json:"newPrice,omitempty"`
+	OldStock      *int      `json:"oldStock,omitempty"`
+	NewStock      *int      `json:"newStock,omitempty"`
+}
+
+func NewProductEvent(eventType string, productID uuid.UUID) *ProductEvent {
+	return &ProductEvent{
+		EventID:    uuid.New(),
+		Type:       eventType,
+		ProductID:  productID,
+		OccurredAt: time.Now(),
+	}
+}
+
+// ChangedFields возвращает имена изменившихся полей товара
+func ChangedFields(before, after *Product) []string {
+	var fields []string
+	if before.SKU != after.SKU {
+		fields = append(fields, "sku")
+	}
+	if before.Name != after.Name {
+		fields = append(fields, "name")
+	}
+	if before.Slug != after.Slug {
+		fields = append(fields, "slug")
+	}
+	if before.Description != after.Description {
+		fields = append(fields, "description")
+	}
+	if before.Price != after.Price {
+		fields = append(fields, "price")
+	}
+	if before.Stock != after.Stock {
+		fields = append(fields, "stock")
+	}
+	if before.Status != after.Status {
+		fields = append(fields, "status")
+	}
+	if before.TaxClass != after.TaxClass {
+		fields = append(fields, "taxClass")
+	}
+	if before.ReorderThreshold != after.ReorderThreshold {
+		fields = append(fields, "reorderThreshold")
+	}
+	if before.TargetStock != after.TargetStock {
+		fields = append(fields, "targetStock")
+	}
+	if !equalAttributes(before.Attributes, after.Attributes) {
+		fields = append(fields, "attributes")
+	}
+
+	return fields
+}
+
+func equalAttributes(a, b []ProductAttribute) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	values := make(map[string]string, len(a))
+	for _, attribute := range a {
+		values[attribute.Code] = attribute.Value
+	}
+	for _, attribute := range b {
+		if value, ok := values[attribute.Code]; !ok || value != attribute.Value {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,255 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	ErrInvalidPatch    = errors.New("invalid patch")
+	ErrPatchTestFailed = errors.New("patch test operation failed")
+)
+
+// Patch частично изменяет товар
+type Patch interface {
+	Apply(product *Product) error
+}
+
+// ProductPatch — JSON Merge Patch (RFC 7396) для товара: nil означает, что поле не передано
+type ProductPatch struct {
+	Name        *string
+	Description *string
+	Price       *float64
+	Stock       *int
+	Status      *ProductStatus
+	TaxClass    *TaxClass
+	// Уровни дозаказа проверяются вместе, поэтому передавать их можно по отдельности
+	ReorderThreshold *int
+	TargetStock      *int
+}
+
+// ParseMergePatch разбирает документ JSON Merge Patch.
+// null допустим только для description и очищает его.
+func ParseMergePatch(data []byte) (*ProductPatch, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	patch := &ProductPatch{}
+	for field, value := range fields {
+		if err := patch.set(field, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return patch, nil
+}
+
+func (p *ProductPatch) set(field string, value json.RawMessage) error {
+	isNull := bytes.Equal(bytes.TrimSpace(value), []byte("null"))
+
+	var target interface{}
+	switch field {
+	case "name":
+		p.Name = new(string)
+		target = p.Name
+	case "description":
+		p.Description = new(string)
+		if isNull {
+			return nil
+		}
+		target = p.Description
+	case "price":
+		p.Price = new(float64)
+		target = p.Price
+	case "stock":
+		p.Stock = new(int)
+		target = p.Stock
+	case "status":
+		p.Status = new(ProductStatus)
+		target = p.Status
+	case "taxClass":
+		p.TaxClass = new(TaxClass)
+		target = p.TaxClass
+	case "reorderThreshold":
+		p.ReorderThreshold = new(int)
+		target = p.ReorderThreshold
+	case "targetStock":
+		p.TargetStock = new(int)
+		target = p.TargetStock
+	default:
+		return fmt.Errorf("%w: unknown field %q", ErrInvalidPatch, field)
+	}
+
+	if isNull {
+		return fmt.Errorf("%w: field %q cannot be removed", ErrInvalidPatch, field)
+	}
+	if err := json.Unmarshal(value, target); err != nil {
+		return fmt.Errorf("%w: field %q: %v", ErrInvalidPatch, field, err)
+	}
+
+	return nil
+}
+
+// Apply применяет переданные поля и проверяет инварианты товара
+func (p *ProductPatch) Apply(product *Product) error {
+	name, description, price, stock := product.Name, product.Description, product.Price, product.Stock
+	if p.Name != nil {
+		name = *p.Name
+	}
+	if p.Description != nil {
+		description = *p.Description
+	}
+	if p.Price != nil {
+		price = *p.Price
+	}
+	if p.Stock != nil {
+		stock = *p.Stock
+	}
+
+	if err := validateProduct(name, price, stock); err != nil {
+		return err
+	}
+	status := product.Status
+	if p.Status != nil {
+		parsed, err := ParseProductStatus(string(*p.Status))
+		if err != nil {
+			return err
+		}
+		status = parsed
+	}
+	taxClass := product.TaxClass
+	if p.TaxClass != nil {
+		parsed, err := ParseTaxClass(string(*p.TaxClass))
+		if err != nil {
+			return err
+		}
+		taxClass = parsed
+	}
+	threshold, target := product.ReorderThreshold, product.TargetStock
+	if p.ReorderThreshold != nil {
+		threshold = *p.ReorderThreshold
+	}
+	if p.TargetStock != nil {
+		target = *p.TargetStock
+	}
+	if err := validateReorderLevels(threshold, target); err != nil {
+		return err
+	}
+
+	product.Name = name
+	product.Description = description
+	product.Price = price
+	product.Stock = stock
+	product.Status = status
+	product.TaxClass = taxClass
+	product.ReorderThreshold = threshold
+	product.TargetStock = target
+	return nil
+}
+
+// PatchOperation — операция JSON Patch (RFC 6902)
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatch — последовательность операций JSON Patch над полями товара.
+// Поддерживаются add, replace, remove и test; move и copy для плоского товара не имеют смысла.
+type JSONPatch []PatchOperation
+
+func ParseJSONPatch(data []byte) (JSONPatch, error) {
+	var ops JSONPatch
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("%w: no operations", ErrInvalidPatch)
+	}
+
+	return ops, nil
+}
+
+// Apply выполняет операции по порядку; при ошибке товар не изменяется
+func (ops JSONPatch) Apply(product *Product) error {
+	working := *product
+	for i, op := range ops {
+		field, ok := patchPathField(op.Path)
+		if !ok {
+			return fmt.Errorf("%w: operation %d: unsupported path %q", ErrInvalidPatch, i, op.Path)
+		}
+
+		switch op.Op {
+		case "add", "replace":
+			if op.Value == nil {
+				return fmt.Errorf("%w: operation %d: value is required", ErrInvalidPatch, i)
+			}
+			patch := &ProductPatch{}
+			if err := patch.set(field, op.Value); err != nil {
+				return err
+			}
+			if err := patch.Apply(&working); err != nil {
+				return err
+			}
+		case "remove":
+			if field != "description" {
+				return fmt.Errorf("%w: operation %d: field %q cannot be removed", ErrInvalidPatch, i, field)
+			}
+			working.Description = ""
+		case "test":
+			current, _ := json.Marshal(productField(&working, field))
+			if !jsonEqual(current, op.Value) {
+				return fmt.Errorf("%w: %s", ErrPatchTestFailed, op.Path)
+			}
+		default:
+			return fmt.Errorf("%w: operation %d: unsupported op %q", ErrInvalidPatch, i, op.Op)
+		}
+	}
+
+	*product = working
+	return nil
+}
+
+func patchPathField(path string) (string, bool) {
+	switch path {
+	case "/name", "/description", "/price", "/stock", "/status", "/taxClass", "/reorderThreshold", "/targetStock":
+		return path[1:], true
+	default:
+		return "", false
+	}
+}
+
+func productField(product *Product, field string) interface{} {
+	switch field {
+	case "name":
+		return product.Name
+	case "description":
+		return product.Description
+	case "price":
+		return product.Price
+	case "status":
+		return product.Status
+	case "taxClass":
+		return product.TaxClass
+	case "reorderThreshold":
+		return product.ReorderThreshold
+	case "targetStock":
+		return product.TargetStock
+	default:
+		return product.Stock
+	}
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	var left, right interface{}
+	if json.Unmarshal(a, &left) != nil || json.Unmarshal(b, &right) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(left, right)
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// CoPurchase — сколько заказов содержали оба товара. Пара хранится в обе стороны,
+// чтобы похожие товары выбирались по одному ProductID.
+type CoPurchase struct {
+	ProductID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	RelatedID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Count     int       `gorm:"not null;default:0"`
+}
+
+// CoPurchaseOrder отмечает заказы, уже учтенные в CoPurchase, чтобы повторная доставка orders.created не увеличивала счетчики
+type CoPurchaseOrder struct {
+	OrderID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	RecordedAt time.Time `gorm:"default:current_timestamp"`
+}
+
+// CoPurchases возвращает пары различных товаров заказа в обе стороны
+func CoPurchases(items []OrderEventItem) []CoPurchase {
+	seen := make(map[uuid.UUID]bool, len(items))
+	products := make([]uuid.UUID, 0, len(items))
+	for _, item := range items {
+		if !seen[item.ProductID] {
+			seen[item.ProductID] = true
+			products = append(products, item.ProductID)
+		}
+	}
+
+	pairs := make([]CoPurchase, 0, len(products)*(len(products)-1))
+	for _, product := range products {
+		for _, related := range products {
+			if product != related {
+				pairs = append(pairs, CoPurchase{ProductID: product, RelatedID: related, Count: 1})
+			}
+		}
+	}
+
+	return pairs
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"math"
+	"time"
+)
+
+const (
+	DefaultSalesWindowDays = 30
+	MaxSalesWindowDays     = 365
+)
+
+var ErrInvalidSalesWindow = errors.New("invalid sales window")
+
+// ProductSale — количество товара в заказе, закэшированное из событий orders.*.
+// Набор учитывается своими компонентами: дозаказывают именно их.
+type ProductSale struct {
+	OrderID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ProductID uuid.UUID `gorm:"type:uuid;primaryKey;index:idx_product_sales_product_sold_at"`
+	Quantity  int       `gorm:"not null"`
+	SoldAt    time.Time `gorm:"not null;index:idx_product_sales_product_sold_at"`
+}
+
+// NewProductSales строит продажи заказа из уже развернутых по компонентам позиций
+func NewProductSales(orderID uuid.UUID, deltas []StockDelta, soldAt time.Time) []ProductSale {
+	sales := make([]ProductSale, 0, len(deltas))
+	for _, delta := range deltas {
+		if delta.Delta > 0 {
+			sales = append(sales, ProductSale{OrderID: orderID, ProductID: delta.ProductID, Quantity: delta.Delta, SoldAt: soldAt})
+		}
+	}
+
+	return sales
+}
+
+func validateReorderLevels(threshold, target int) error {
+	if threshold < 0 || target < 0 {
+		return fmt.Errorf("%w: reorder threshold and target stock cannot be negative", ErrInvalidProduct)
+	}
+	if target != 0 && target <= threshold {
+		return fmt.Errorf("%w: target stock must be above the reorder threshold", ErrInvalidProduct)
+	}
+
+	return nil
+}
+
+// ReplenishmentLine — строка отчета о дозаказе
+type ReplenishmentLine struct {
+	ProductID         uuid.UUID
+	SKU               string
+	Name              string
+	Stock             int
+	ReorderThreshold  int
+	TargetStock       int
+	Sold              int     // Продано за последние Days дней
+	DailySales        float64 // Средние продажи в день за тот же период
+	SuggestedQuantity int
+}
+
+// NewReplenishmentLine предлагает дозаказать товар до целевого уровня, но не меньше,
+// чем нужно, чтобы при продажах как за последние days дней остаток не опустился ниже порога.
+// Без целевого уровня остаток доводится до порога с тем же запасом на продажи.
+func NewReplenishmentLine(product *Product, sold, days int) ReplenishmentLine {
+	desired := max(product.TargetStock, product.ReorderThreshold, product.ReorderThreshold+sold)
+
+	return ReplenishmentLine{
+		ProductID:         product.ID,
+		SKU:               product.SKU,
+		Name:              product.Name,
+		Stock:             product.Stock,
+		ReorderThreshold:  product.ReorderThreshold,
+		TargetStock:       product.TargetStock,
+		Sold:              sold,
+		DailySales:        math.Round(float64(sold)/float64(days)*100) / 100,
+		SuggestedQuantity: max(desired-product.Stock, 0),
+	}
+}
+
+// SalesWindowStart возвращает начало периода продаж в days дней; 0 означает период по умолчанию
+func SalesWindowStart(now time.Time, days int) (time.Time, int, error) {
+	if days == 0 {
+		days = DefaultSalesWindowDays
+	}
+	if days < 0 || days > MaxSalesWindowDays {
+		return time.Time{}, 0, fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidSalesWindow, MaxSalesWindowDays)
+	}
+
+	return now.AddDate(0, 0, -days), days, nil
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+var (
+	ErrInsufficientStock     = errors.New("insufficient stock")
+	ErrReservationNotActive  = errors.New("reservation is not active")
+	ErrReservationNotFound   = errors.New("reservation not found")
+	ErrReservationDuplicated = errors.New("reservation for this reference already exists")
+	ErrReservationForbidden  = errors.New("reservation belongs to another user")
+	ErrInvalidReservation    = errors.New("invalid reservation")
+	ErrReservationLimit      = errors.New("too many active reservations")
+)
+
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "active"
+	ReservationCommitted ReservationStatus = "committed"
+	ReservationReleased  ReservationStatus = "released"
+)
+
+// Reservation удерживает остатки товаров под внешний идентификатор (заказ, корзину) до ExpiresAt
+type Reservation struct {
+	ID          uuid.UUID         `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	ReferenceID string            `gorm:"not null;uniqueIndex"`
+	Status      ReservationStatus `gorm:"not null;default:'active';index"`
+	ExpiresAt   time.Time         `gorm:"not null;index"`
+	CreatedBy   string            `gorm:"not null;default:''"` // Email пользователя; пусто, если резерв создал сервис
+	CreatedAt   time.Time         `gorm:"default:current_timestamp"`
+	UpdatedAt   time.Time
+	Items       []ReservationItem
+}
+
+type ReservationItem struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	ReservationID uuid.UUID `gorm:"type:uuid;not null;index"`
+	ProductID     uuid.UUID `gorm:"type:uuid;not null"`
+	Quantity      int       `gorm:"not null"`
+	// Склад, с которого списан остаток. Если одного склада не хватило, товар занимает несколько позиций.
+	// Пусто у резервов, созданных до появления складов.
+	WarehouseID *uuid.UUID `gorm:"type:uuid"`
+}
+
+func NewReservation(referenceID string, ttl time.Duration) (*Reservation, error) {
+	if referenceID == "" {
+		return nil, fmt.Errorf("%w: reference ID cannot be empty", ErrInvalidReservation)
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("%w: ttl must be positive", ErrInvalidReservation)
+	}
+
+	now := time.Now()
+	return &Reservation{
+		ID:          uuid.New(),
+		ReferenceID: referenceID,
+		Status:      ReservationActive,
+		ExpiresAt:   now.Add(ttl),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// AddItem добавляет товар в резерв, объединяя количество для одинаковых товаров
+func (r *Reservation) AddItem(productID uuid.UUID, quantity int) error {
+	if quantity <= 0 {
+		return fmt.Errorf("%w: quantity must be positive", ErrInvalidReservation)
+	}
+
+	for i := range r.Items {
+		if r.Items[i].ProductID == productID {
+			r.Items[i].Quantity += quantity
+			return nil
+		}
+	}
+
+	r.Items = append(r.Items, ReservationItem{
+		ID:            uuid.New(),
+		ReservationID: r.ID,
+		ProductID:     productID,
+		Quantity:      quantity,
+	})
+	return nil
+}
+
+// CanManage сообщает, может ли actor читать, подтверждать и отменять резерв.
+// Сервисы и администраторы управляют любым резервом, пользователь — только своим.
+func (r *Reservation) CanManage(actor Actor) bool {
+	if actor.IsService || actor.IsAdmin {
+		return true
+	}
+
+	return r.CreatedBy != "" && r.CreatedBy == NormalizeEmail(actor.Email)
+}
+
+func (r *Reservation) IsExpired(now time.Time) bool {
+	return r.Status == ReservationActive && !now.Before(r.ExpiresAt)
+}
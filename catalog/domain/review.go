@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+var (
+	ErrReviewNotFound  = errors.New("review not found")
+	ErrReviewExists    = errors.New("review for this product already exists")
+	ErrReviewForbidden = errors.New("review belongs to another user")
+	ErrNotPurchased    = errors.New("product was not delivered to this user")
+	ErrInvalidReview   = errors.New("invalid review")
+)
+
+// Review — отзыв покупателя; один пользователь оставляет не больше одного отзыва на товар
+type Review struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	ProductID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_reviews_product_user"`
+	UserEmail string    `gorm:"not null;uniqueIndex:idx_reviews_product_user"`
+	Rating    int       `gorm:"not null"`
+	Title     string
+	Comment   string
+	CreatedAt time.Time `gorm:"default:current_timestamp;index"`
+	UpdatedAt time.Time
+}
+
+func NewReview(productID uuid.UUID, userEmail string, rating int, title, comment string) (*Review, error) {
+	review := &Review{
+		ID:        uuid.New(),
+		ProductID: productID,
+		UserEmail: NormalizeEmail(userEmail),
+		CreatedAt: time.Now(),
+	}
+	if err := review.Edit(rating, title, comment); err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
+
+// Edit меняет оценку и текст отзыва
+func (r *Review) Edit(rating int, title, comment string) error {
+	if rating < MinRating || rating > MaxRating {
+		return fmt.Errorf("%w: rating must be between %d and %d", ErrInvalidReview, MinRating, MaxRating)
+	}
+
+	r.Rating = rating
+	r.Title = strings.TrimSpace(title)
+	r.Comment = strings.TrimSpace(comment)
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+// Purchase — позиция заказа пользователя, закэшированная из событий orders.*.
+// По ней проверяется право оставить отзыв.
+type Purchase struct {
+	OrderID     uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ProductID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserEmail   string    `gorm:"not null;index"`
+	DeliveredAt *time.Time
+	CreatedAt   time.Time `gorm:"default:current_timestamp"`
+}
+
+// NewPurchases возвращает позиции заказа; deliveredAt пуст, пока заказ не доставлен
+func NewPurchases(order *OrderEvent, deliveredAt *time.Time) []Purchase {
+	purchases := make([]Purchase, 0, len(order.Items))
+	for _, item := range order.Items {
+		purchases = append(purchases, Purchase{
+			OrderID:     order.ID,
+			ProductID:   item.ProductID,
+			UserEmail:   NormalizeEmail(order.UserEmail),
+			DeliveredAt: deliveredAt,
+			CreatedAt:   time.Now(),
+		})
+	}
+
+	return purchases
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+var (
+	ErrSellerNotFound  = errors.New("seller not found")
+	ErrNotProductOwner = errors.New("product belongs to another seller")
+)
+
+// Seller — продавец маркетплейса. Заводится при первом товаре пользователя;
+// витрина показывает только ID, чтобы не раскрывать email.
+type Seller struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Email     string    `gorm:"not null;uniqueIndex"`
+	CreatedAt time.Time `gorm:"default:current_timestamp"`
+}
+
+func NewSeller(email string) *Seller {
+	return &Seller{
+		ID:        uuid.New(),
+		Email:     NormalizeEmail(email),
+		CreatedAt: time.Now(),
+	}
+}
+
+// NormalizeEmail приводит email к виду, в котором он хранится у продавца
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Actor — пользователь, от имени которого меняется каталог.
+// IsService означает, что запрос пришел от другого сервиса по X-Service-Token, а не от пользователя.
+type Actor struct {
+	Email     string
+	IsAdmin   bool
+	IsService bool
+}
+
+// CanEdit сообщает, может ли actor менять товар продавца seller.
+// Администратор меняет любой товар; товары без продавца, созданные до маркетплейса, — только он.
+func (p *Product) CanEdit(actor Actor, seller *Seller) bool {
+	if actor.IsAdmin {
+		return true
+	}
+
+	return p.SellerID != nil && seller != nil && *p.SellerID == seller.ID
+}
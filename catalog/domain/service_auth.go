@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"crypto/subtle"
+	"os"
+	"strings"
+)
+
+// IsServiceToken проверяет токен межсервисного доступа по списку SERVICE_TOKENS.
+// Токенов может быть несколько, чтобы менять их без одновременного перезапуска всех сервисов.
+func IsServiceToken(token string) bool {
+	if token == "" {
+		return false
+	}
+
+	for _, allowed := range strings.Split(os.Getenv("SERVICE_TOKENS"), ",") {
+		allowed = strings.TrimSpace(allowed)
+		if allowed != "" && subtle.ConstantTimeCompare([]byte(allowed), []byte(token)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxSlugLength = 80
+
+// transliteration — кириллица в духе ссылок Яндекса и латиница без диакритики
+var transliteration = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+	// Украинские и белорусские буквы
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u",
+	// Латиница с диакритикой из названий брендов
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae", 'ç': "c",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i",
+	'ñ': "n", 'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'œ': "oe",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ý': "y", 'ÿ': "y", 'ß': "ss",
+}
+
+// ProductSlug — прежний slug товара. По нему товар по-прежнему находится, но клиенту
+// отдается перенаправление на текущий slug.
+type ProductSlug struct {
+	Slug      string    `gorm:"primaryKey"`
+	ProductID uuid.UUID `gorm:"type:uuid;not null;index"`
+	CreatedAt time.Time `gorm:"default:current_timestamp"`
+}
+
+// Slugify строит из названия часть URL: латиница в нижнем регистре, цифры и дефисы
+func Slugify(name string) string {
+	var slug strings.Builder
+	separated := true
+	for _, r := range strings.ToLower(name) {
+		if latin, ok := transliteration[r]; ok {
+			slug.WriteString(latin)
+			separated = separated && latin == ""
+			continue
+		}
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			slug.WriteRune(r)
+			separated = false
+			continue
+		}
+		if !separated {
+			slug.WriteByte('-')
+			separated = true
+		}
+	}
+
+	result := slug.String()
+	if len(result) > maxSlugLength {
+		result = result[:maxSlugLength]
+	}
+	result = strings.Trim(result, "-")
+	if result == "" {
+		return "product"
+	}
+
+	return result
+}
+
+// SlugWithSuffix добавляет к занятому slug номер: kruzhka, kruzhka-2, kruzhka-3
+func SlugWithSuffix(slug string, n int) string {
+	if n <= 1 {
+		return slug
+	}
+
+	suffix := "-" + strconv.Itoa(n)
+	if len(slug)+len(suffix) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength-len(suffix)], "-")
+	}
+	return slug + suffix
+}
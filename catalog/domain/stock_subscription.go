@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"time"
+)
+
+const TopicProductBackInStock = "product.back_in_stock"
+
+var (
+	ErrProductInStock       = errors.New("product is in stock")
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+)
+
+// StockSubscription — просьба покупателя сообщить, когда товар снова появится в наличии.
+// Подписка одноразовая: после уведомления она удаляется.
+type StockSubscription struct {
+	ProductID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserEmail string    `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"default:current_timestamp"`
+}
+
+func NewStockSubscription(productID uuid.UUID, userEmail string) *StockSubscription {
+	return &StockSubscription{
+		ProductID: productID,
+		UserEmail: userEmail,
+		CreatedAt: time.Now(),
+	}
+}
+
+// BackInStockEvent публикуется в product.back_in_stock, когда остаток товара
+// с подписчиками становится положительным
+type BackInStockEvent struct {
+	EventID     uuid.UUID `json:"eventId"`
+	ProductID   uuid.UUID `json:"productId"`
+	Stock       int       `json:"stock"`
+	Subscribers []string  `json:"subscribers"`
+	OccurredAt  time.Time `json:"occurredAt"`
+}
+
+func NewBackInStockEvent(productID uuid.UUID, stock int, subscribers []string) *BackInStockEvent {
+	return &BackInStockEvent{
+		EventID:     uuid.New(),
+		ProductID:   productID,
+		Stock:       stock,
+		Subscribers: subscribers,
+		OccurredAt:  time.Now(),
+	}
+}
@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"math"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidTaxRate  = errors.New("invalid tax rate")
+	ErrTaxRateNotFound = errors.New("tax rate not found")
+)
+
+type TaxClass string
+
+const (
+	TaxStandard TaxClass = "standard"
+	TaxReduced  TaxClass = "reduced"
+	TaxExempt   TaxClass = "exempt" // Ставка всегда нулевая, в таблице ставок не задается
+)
+
+func ParseTaxClass(class string) (TaxClass, error) {
+	switch TaxClass(class) {
+	case TaxStandard, TaxReduced, TaxExempt:
+		return TaxClass(class), nil
+	default:
+		return "", fmt.Errorf("%w: unknown tax class %q", ErrInvalidProduct, class)
+	}
+}
+
+// TaxRate — ставка налога для класса товаров в стране или ее регионе.
+// Ставка с пустым Region действует во всей стране, если для региона нет своей.
+type TaxRate struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Country   string    `gorm:"not null;uniqueIndex:idx_tax_rates_destination"` // ISO 3166-1 alpha-2
+	Region    string    `gorm:"not null;default:'';uniqueIndex:idx_tax_rates_destination"`
+	TaxClass  TaxClass  `gorm:"not null;uniqueIndex:idx_tax_rates_destination"`
+	Rate      float64   `gorm:"not null;type:numeric"` // Проценты, например 20 для НДС 20%
+	UpdatedAt time.Time
+}
+
+func NewTaxRate(country, region, class string, rate float64) (*TaxRate, error) {
+	destination, err := ParseTaxDestination(country, region)
+	if err != nil {
+		return nil, err
+	}
+	taxClass, err := ParseTaxClass(class)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown tax class %q", ErrInvalidTaxRate, class)
+	}
+	if taxClass == TaxExempt {
+		return nil, fmt.Errorf("%w: exempt products are never taxed", ErrInvalidTaxRate)
+	}
+	if rate < 0 || rate > 100 {
+		return nil, fmt.Errorf("%w: rate must be between 0 and 100 percent", ErrInvalidTaxRate)
+	}
+
+	return &TaxRate{
+		ID:        uuid.New(),
+		Country:   destination.Country,
+		Region:    destination.Region,
+		TaxClass:  taxClass,
+		Rate:      rate,
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+// TaxDestination — страна и регион доставки, по которым выбирается ставка
+type TaxDestination struct {
+	Country string
+	Region  string
+}
+
+func ParseTaxDestination(country, region string) (TaxDestination, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if len(country) != 2 {
+		return TaxDestination{}, fmt.Errorf("%w: country must be a 2-letter ISO code", ErrInvalidTaxRate)
+	}
+
+	return TaxDestination{Country: country, Region: strings.ToUpper(strings.TrimSpace(region))}, nil
+}
+
+// SelectTaxRate выбирает ставку для класса: сначала региональную, затем общую для страны
+func SelectTaxRate(rates []*TaxRate, destination TaxDestination, class TaxClass) (float64, error) {
+	if class == TaxExempt {
+		return 0, nil
+	}
+
+	var countryRate *TaxRate
+	for _, rate := range rates {
+		if rate.Country != destination.Country || rate.TaxClass != class {
+			continue
+		}
+		if destination.Region != "" && rate.Region == destination.Region {
+			return rate.Rate, nil
+		}
+		if rate.Region == "" {
+			countryRate = rate
+		}
+	}
+	if countryRate == nil {
+		return 0, fmt.Errorf("%w: no %s rate for %s", ErrTaxRateNotFound, class, destination.Country)
+	}
+
+	return countryRate.Rate, nil
+}
+
+// ApplyTax заполняет цены с налогом и без него по ставке rate (в процентах).
+// includesTax сообщает, включен ли налог в Price.
+func (p *Product) ApplyTax(rate float64, includesTax bool) {
+	including, excluding := p.Price, p.Price
+	if includesTax {
+		excluding = roundPrice(p.Price / (1 + rate/100))
+	} else {
+		including = roundPrice(p.Price * (1 + rate/100))
+	}
+
+	p.TaxRate = &rate
+	p.PriceIncludingTax = &including
+	p.PriceExcludingTax = &excluding
+}
+
+func roundPrice(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTranslationNotFound = errors.New("translation not found")
+	ErrInvalidTranslation  = errors.New("invalid translation")
+)
+
+// localePattern принимает теги вида ru, en, en-gb, zh-hant
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+// ProductTranslation — название и описание товара на другом языке.
+// Сам товар хранит текст на языке каталога по умолчанию.
+type ProductTranslation struct {
+	ProductID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Locale      string    `gorm:"primaryKey;index"`
+	Name        string    `gorm:"not null"`
+	Description string
+	UpdatedAt   time.Time
+}
+
+func NewProductTranslation(productID uuid.UUID, locale, name, description string) (*ProductTranslation, error) {
+	locale, err := ParseLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("%w: name cannot be empty", ErrInvalidTranslation)
+	}
+
+	return &ProductTranslation{
+		ProductID:   productID,
+		Locale:      locale,
+		Name:        strings.TrimSpace(name),
+		Description: description,
+		UpdatedAt:   time.Now(),
+	}, nil
+}
+
+// ParseLocale приводит тег языка к виду, в котором он хранится: нижний регистр, дефис вместо подчеркивания
+func ParseLocale(locale string) (string, error) {
+	normalized := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-")
+	if !localePattern.MatchString(normalized) {
+		return "", fmt.Errorf("%w: unsupported locale %q", ErrInvalidTranslation, locale)
+	}
+
+	return normalized, nil
+}
+
+// ParseAcceptLanguage возвращает языки из заголовка Accept-Language в порядке предпочтения.
+// Неразборчивые теги и теги с q=0 пропускаются.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		locale, err := ParseLocale(tag)
+		if err != nil || q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{locale: locale, q: q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	locales := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		locales = append(locales, tag.locale)
+	}
+	return locales
+}
+
+// LocaleFallbacks строит цепочку поиска перевода: каждый язык, затем его основной язык
+// (en-gb → en), и в конце язык каталога по умолчанию. Повторы удаляются.
+func LocaleFallbacks(preferred []string, defaultLocale string) []string {
+	seen := make(map[string]bool)
+	var chain []string
+	add := func(locale string) {
+		if !seen[locale] {
+			seen[locale] = true
+			chain = append(chain, locale)
+		}
+	}
+
+	for _, locale := range preferred {
+		add(locale)
+		if base, _, ok := strings.Cut(locale, "-"); ok {
+			add(base)
+		}
+	}
+	add(defaultLocale)
+
+	return chain
+}
+
+// Localize подставляет в товар текст на первом языке цепочки, для которого есть перевод.
+// Язык по умолчанию хранится в самом товаре, поэтому на нем цепочка всегда заканчивается.
+func (p *Product) Localize(chain []string, defaultLocale string, translations map[string]*ProductTranslation) {
+	for _, locale := range chain {
+		if locale == defaultLocale {
+			break
+		}
+		if translation, ok := translations[locale]; ok {
+			p.Name = translation.Name
+			p.Description = translation.Description
+			p.Locale = locale
+			return
+		}
+	}
+	p.Locale = defaultLocale
+}
@@ -0,0 +1,198 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrWarehouseNotFound = errors.New("warehouse not found")
+	ErrWarehouseExists   = errors.New("warehouse with this code already exists")
+	ErrWarehouseNotEmpty = errors.New("warehouse still holds stock")
+	ErrNoWarehouse       = errors.New("no active warehouse")
+	ErrInvalidWarehouse  = errors.New("invalid warehouse")
+	ErrInvalidAdjustment = errors.New("invalid stock adjustment")
+)
+
+// DefaultWarehouseCode — склад, который создается при первом запуске и принимает существующие остатки
+const DefaultWarehouseCode = "main"
+
+var warehouseCodePattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// Warehouse — склад, с которого отгружаются товары.
+// Резервы и списания забирают остатки со складов в порядке Priority (меньше — раньше).
+type Warehouse struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Code      string    `gorm:"not null;uniqueIndex"`
+	Name      string    `gorm:"not null"`
+	Priority  int       `gorm:"not null;default:0"`
+	Active    bool      `gorm:"not null;default:true"` // Неактивный склад не принимает товары; отключить можно только пустой склад
+	CreatedAt time.Time `gorm:"default:current_timestamp"`
+}
+
+func NewWarehouse(code, name string, priority int) (*Warehouse, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if !warehouseCodePattern.MatchString(code) {
+		return nil, fmt.Errorf("%w: code must match %s", ErrInvalidWarehouse, warehouseCodePattern)
+	}
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidWarehouse)
+	}
+
+	return &Warehouse{
+		ID:        uuid.New(),
+		Code:      code,
+		Name:      strings.TrimSpace(name),
+		Priority:  priority,
+		Active:    true,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// WarehouseStock — остаток товара на складе, доступный для продажи.
+// Сумма по складам всегда равна Product.Stock.
+type WarehouseStock struct {
+	WarehouseID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ProductID   uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	Quantity    int       `gorm:"not null;default:0"`
+	UpdatedAt   time.Time
+}
+
+// WarehouseStockLevel — остаток товара на складе для ответа API
+type WarehouseStockLevel struct {
+	WarehouseID uuid.UUID
+	Code        string
+	Name        string
+	Priority    int
+	Available   int // Можно продать
+	Reserved    int // Удерживается активными резервами
+}
+
+// ProductStock — остатки товара по складам; Available — доступно к продаже по всем складам
+type ProductStock struct {
+	ProductID  uuid.UUID
+	Available  int
+	Reserved   int
+	Warehouses []WarehouseStockLevel
+}
+
+func NewProductStock(productID uuid.UUID, levels []WarehouseStockLevel) *ProductStock {
+	stock := &ProductStock{ProductID: productID, Warehouses: levels}
+	for _, level := range levels {
+		stock.Available += level.Available
+		stock.Reserved += level.Reserved
+	}
+
+	return stock
+}
+
+type StockAdjustmentReason string
+
+const (
+	// Причины ручных корректировок
+	AdjustmentReceived   StockAdjustmentReason = "received"
+	AdjustmentReturned   StockAdjustmentReason = "returned"
+	AdjustmentDamaged    StockAdjustmentReason = "damaged"
+	AdjustmentLost       StockAdjustmentReason = "lost"
+	AdjustmentStockCount StockAdjustmentReason = "stock_count"
+	AdjustmentCorrection StockAdjustmentReason = "correction"
+
+	// Движения, которые записывает сам каталог
+	AdjustmentTransferOut        StockAdjustmentReason = "transfer_out"
+	AdjustmentTransferIn         StockAdjustmentReason = "transfer_in"
+	AdjustmentCatalogEdit        StockAdjustmentReason = "catalog_edit"
+	AdjustmentReservation        StockAdjustmentReason = "reservation"
+	AdjustmentReservationRelease StockAdjustmentReason = "reservation_release"
+	AdjustmentOrder              StockAdjustmentReason = "order"
+	AdjustmentOrderCancelled     StockAdjustmentReason = "order_cancelled"
+	AdjustmentOrderRefunded      StockAdjustmentReason = "order_refunded"
+)
+
+// ParseAdjustmentReason принимает только причины, доступные для ручной корректировки
+func ParseAdjustmentReason(reason string) (StockAdjustmentReason, error) {
+	switch StockAdjustmentReason(reason) {
+	case AdjustmentReceived, AdjustmentReturned, AdjustmentDamaged, AdjustmentLost, AdjustmentStockCount, AdjustmentCorrection:
+		return StockAdjustmentReason(reason), nil
+	default:
+		return "", fmt.Errorf("%w: unknown reason %q", ErrInvalidAdjustment, reason)
+	}
+}
+
+// StockAdjustmentReasonFor возвращает причину движения остатков по событию заказа
+func StockAdjustmentReasonFor(topic string) StockAdjustmentReason {
+	switch topic {
+	case TopicOrderCancelled:
+		return AdjustmentOrderCancelled
+	case TopicOrderRefunded:
+		return AdjustmentOrderRefunded
+	default:
+		return AdjustmentOrder
+	}
+}
+
+// StockAdjustment — запись журнала движений остатков. Журнал только пополняется.
+type StockAdjustment struct {
+	ID            uuid.UUID             `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	ProductID     uuid.UUID             `gorm:"type:uuid;not null;index"`
+	WarehouseID   uuid.UUID             `gorm:"type:uuid;not null;index"`
+	Delta         int                   `gorm:"not null"`
+	QuantityAfter int                   `gorm:"not null"` // Остаток на складе после движения
+	Reason        StockAdjustmentReason `gorm:"not null;index"`
+	Reference     string                // Резерв, событие заказа или ID перемещения
+	Note          string
+	Actor         string    // Email пользователя; пусто для движений, записанных системой
+	CreatedAt     time.Time `gorm:"default:current_timestamp;index"`
+}
+
+func NewStockAdjustment(productID, warehouseID uuid.UUID, delta int, reason StockAdjustmentReason, reference string) *StockAdjustment {
+	return &StockAdjustment{
+		ID:          uuid.New(),
+		ProductID:   productID,
+		WarehouseID: warehouseID,
+		Delta:       delta,
+		Reason:      reason,
+		Reference:   reference,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// StockAdjustmentFilter ограничивает выборку журнала; пустые поля не фильтруют
+type StockAdjustmentFilter struct {
+	ProductID   uuid.UUID
+	WarehouseID uuid.UUID
+	Reason      StockAdjustmentReason
+}
+
+// StockTransfer — перемещение товара между складами; общий остаток товара не меняется
+type StockTransfer struct {
+	ID              uuid.UUID
+	ProductID       uuid.UUID
+	FromWarehouseID uuid.UUID
+	ToWarehouseID   uuid.UUID
+	Quantity        int
+	Note            string
+	Actor           string
+}
+
+func NewStockTransfer(productID, from, to uuid.UUID, quantity int, note, actor string) (*StockTransfer, error) {
+	if quantity <= 0 {
+		return nil, fmt.Errorf("%w: quantity must be positive", ErrInvalidAdjustment)
+	}
+	if from == to {
+		return nil, fmt.Errorf("%w: source and destination warehouses must differ", ErrInvalidAdjustment)
+	}
+
+	return &StockTransfer{
+		ID:              uuid.New(),
+		ProductID:       productID,
+		FromWarehouseID: from,
+		ToWarehouseID:   to,
+		Quantity:        quantity,
+		Note:            note,
+		Actor:           actor,
+	}, nil
+}
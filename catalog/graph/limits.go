@@ -0,0 +1,165 @@
+package graph
+
+import (
+	"fmt"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"strconv"
+	"strings"
+)
+
+// defaultListSize — сколько элементов предполагается в списке, размер которого не задан аргументом
+const defaultListSize = 10
+
+// queryCost считает глубину и сложность запроса до выполнения.
+// Каждое поле стоит 1, поля-списки умножают стоимость вложенных полей на число элементов.
+type queryCost struct {
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// checkLimits отклоняет запрос, если его глубина или сложность больше допустимых.
+// Поля интроспекции не учитываются: их размер ограничен схемой.
+func checkLimits(schema *graphql.Schema, document *ast.Document, operationName string, variables map[string]interface{}, maxDepth, maxComplexity int) error {
+	cost := queryCost{schema: schema, fragments: make(map[string]*ast.FragmentDefinition), variables: variables}
+	var operation *ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			cost.fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operation = definition
+			}
+		}
+	}
+	if operation == nil || operation.Operation != ast.OperationTypeQuery {
+		// Ошибку неизвестной операции вернет выполнение запроса
+		return nil
+	}
+
+	complexity, depth := cost.selectionSet(operation.SelectionSet, schema.QueryType())
+	if depth > maxDepth {
+		return fmt.Errorf("query depth %d exceeds the limit of %d", depth, maxDepth)
+	}
+	if complexity > maxComplexity {
+		return fmt.Errorf("query complexity %d exceeds the limit of %d", complexity, maxComplexity)
+	}
+
+	return nil
+}
+
+func (q queryCost) selectionSet(selectionSet *ast.SelectionSet, parent graphql.Type) (complexity, depth int) {
+	if selectionSet == nil {
+		return 0, 0
+	}
+
+	for _, selection := range selectionSet.Selections {
+		var fieldComplexity, fieldDepth int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			fieldComplexity, fieldDepth = q.field(selection, parent)
+		case *ast.InlineFragment:
+			fragmentType := parent
+			if selection.TypeCondition != nil {
+				fragmentType = q.schema.Type(selection.TypeCondition.Name.Value)
+			}
+			fieldComplexity, fieldDepth = q.selectionSet(selection.SelectionSet, fragmentType)
+		case *ast.FragmentSpread:
+			if fragment, ok := q.fragments[selection.Name.Value]; ok {
+				fieldComplexity, fieldDepth = q.selectionSet(fragment.SelectionSet, q.schema.Type(fragment.TypeCondition.Name.Value))
+			}
+		}
+
+		complexity += fieldComplexity
+		if fieldDepth > depth {
+			depth = fieldDepth
+		}
+	}
+
+	return complexity, depth
+}
+
+func (q queryCost) field(field *ast.Field, parent graphql.Type) (complexity, depth int) {
+	if strings.HasPrefix(field.Name.Value, "__") {
+		return 0, 0
+	}
+	object, ok := parent.(*graphql.Object)
+	if !ok {
+		return 1, 1
+	}
+	definition, ok := object.Fields()[field.Name.Value]
+	if !ok {
+		return 1, 1
+	}
+
+	fieldType, isList := unwrapType(definition.Type)
+	childComplexity, childDepth := q.selectionSet(field.SelectionSet, fieldType)
+	if isList {
+		childComplexity *= q.listSize(field)
+	}
+
+	return 1 + childComplexity, 1 + childDepth
+}
+
+// listSize берет размер списка из аргумента limit или из длины списка ids
+func (q queryCost) listSize(field *ast.Field) int {
+	for _, argument := range field.Arguments {
+		value := q.argumentValue(argument.Value)
+		switch argument.Name.Value {
+		case "limit":
+			if limit, ok := value.(int); ok && limit > 0 {
+				return limit
+			}
+		case "ids":
+			if ids, ok := value.([]interface{}); ok {
+				return len(ids)
+			}
+		}
+	}
+
+	return defaultListSize
+}
+
+func (q queryCost) argumentValue(value ast.Value) interface{} {
+	switch value := value.(type) {
+	case *ast.Variable:
+		variable := q.variables[value.Name.Value]
+		// Числа из JSON приходят как float64
+		if number, ok := variable.(float64); ok {
+			return int(number)
+		}
+		return variable
+	case *ast.IntValue:
+		number, err := strconv.Atoi(value.Value)
+		if err != nil {
+			return nil
+		}
+		return number
+	case *ast.ListValue:
+		values := make([]interface{}, len(value.Values))
+		for i, item := range value.Values {
+			values[i] = q.argumentValue(item)
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// unwrapType снимает NonNull и List и сообщает, был ли тип списком
+func unwrapType(fieldType graphql.Type) (graphql.Type, bool) {
+	isList := false
+	for {
+		switch wrapped := fieldType.(type) {
+		case *graphql.NonNull:
+			fieldType = wrapped.OfType
+		case *graphql.List:
+			isList = true
+			fieldType = wrapped.OfType
+		default:
+			return fieldType, isList
+		}
+	}
+}
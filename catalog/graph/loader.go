@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/service"
+)
+
+// productLoader собирает ID товаров, запрошенных резолверами одного уровня запроса,
+// и загружает их одним пакетным вызовом каталога. Резолвер получает thunk: исполнитель
+// graphql-go вызывает thunk-и только после обхода всего уровня, поэтому к первому вызову
+// все ID уровня уже собраны. Исполнитель работает в одной горутине, блокировки не нужны.
+type productLoader struct {
+	fetch   func(ids []string) ([]*domain.Product, error)
+	pending []string
+	loaded  map[string]*domain.Product // nil — товар не найден или не публичен
+	failed  map[string]error
+}
+
+func newProductLoader(fetch func(ids []string) ([]*domain.Product, error)) *productLoader {
+	return &productLoader{
+		fetch:  fetch,
+		loaded: make(map[string]*domain.Product),
+		failed: make(map[string]error),
+	}
+}
+
+// Load откладывает загрузку товара до первого вызова возвращенного thunk-а
+func (l *productLoader) Load(productID uuid.UUID) func() (interface{}, error) {
+	id := productID.String()
+	if _, ok := l.loaded[id]; !ok && l.failed[id] == nil {
+		l.pending = append(l.pending, id)
+	}
+
+	return func() (interface{}, error) {
+		l.flush()
+		if err := l.failed[id]; err != nil {
+			return nil, err
+		}
+		if product := l.loaded[id]; product != nil {
+			return product, nil
+		}
+		return nil, nil
+	}
+}
+
+// flush загружает накопленные ID порциями не больше service.MaxBatchGetSize
+func (l *productLoader) flush() {
+	seen := make(map[string]bool, len(l.pending))
+	var ids []string
+	for _, id := range l.pending {
+		if _, ok := l.loaded[id]; !ok && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	l.pending = nil
+
+	for start := 0; start < len(ids); start += service.MaxBatchGetSize {
+		end := min(start+service.MaxBatchGetSize, len(ids))
+		batch := ids[start:end]
+		products, err := l.fetch(batch)
+		if err != nil {
+			for _, id := range batch {
+				l.failed[id] = err
+			}
+			continue
+		}
+
+		for _, id := range batch {
+			l.loaded[id] = nil
+		}
+		for _, product := range products {
+			l.loaded[product.ID.String()] = product
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+	"github.com/yangirxd/store-app/catalog/client"
+	"github.com/yangirxd/store-app/catalog/domain"
+)
+
+// newSchema описывает схему витрины. Поля без Resolve читаются из одноименных полей структур.
+func newSchema() (graphql.Schema, error) {
+	var productType *graphql.Object
+	productType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Product",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":                &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+				"sku":               &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"slug":              &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"name":              &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"description":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"locale":            &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "Language of name and description"},
+				"price":             &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+				"currency":          &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"taxClass":          &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"taxRate":           &graphql.Field{Type: graphql.Float, Description: "Tax rate in percent, set when the request has a destination country"},
+				"priceIncludingTax": &graphql.Field{Type: graphql.Float},
+				"priceExcludingTax": &graphql.Field{Type: graphql.Float},
+				"stock":             &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"status":            &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"type":              &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"sellerId":          &graphql.Field{Type: graphql.ID},
+				"ratingAverage":     &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+				"ratingCount":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"updatedAt":         &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+				"related": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(productType))),
+					Description: "Products frequently bought together with this one",
+					Args: graphql.FieldConfigArgument{
+						"limit": &graphql.ArgumentConfig{Type: graphql.Int},
+					},
+					Resolve: resolveRelated,
+				},
+			}
+		}),
+	})
+
+	basketItemType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "BasketItem",
+		Fields: graphql.Fields{
+			"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"productId": &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"quantity":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"product": &graphql.Field{
+				Type:        productType,
+				Description: "Null if the product is no longer in the public catalog",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return stateFrom(p.Context).products.Load(p.Source.(client.BasketItem).ProductID), nil
+				},
+			},
+		},
+	})
+	basketType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Basket",
+		Fields: graphql.Fields{
+			"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"items":     &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(basketItemType)))},
+		},
+	})
+
+	orderItemType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "OrderItem",
+		Fields: graphql.Fields{
+			"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"productId": &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"quantity":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"price":     &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Description: "Price at the time of the order"},
+			"product": &graphql.Field{
+				Type:        productType,
+				Description: "Current state of the product; null if it is no longer in the public catalog",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return stateFrom(p.Context).products.Load(p.Source.(client.OrderItem).ProductID), nil
+				},
+			},
+		},
+	})
+	orderType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Order",
+		Fields: graphql.Fields{
+			"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"total":     &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"items":     &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(orderItemType)))},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"product": &graphql.Field{
+				Type: productType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := uuid.Parse(p.Args["id"].(string))
+					if err != nil {
+						return nil, fmt.Errorf("invalid UUID: %v", err)
+					}
+					return stateFrom(p.Context).products.Load(id), nil
+				},
+			},
+			"productBySlug": &graphql.Field{
+				Type: productType,
+				Args: graphql.FieldConfigArgument{
+					"slug": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: resolveProductBySlug,
+			},
+			"products": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(productType)),
+				Description: "Products in the order of ids; null for products that are not in the public catalog",
+				Args: graphql.FieldConfigArgument{
+					"ids": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID)))},
+				},
+				Resolve: resolveProducts,
+			},
+			"basket": &graphql.Field{
+				Type:        basketType,
+				Description: "Basket of the authenticated user; null if the user has no basket yet",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					basket, err := stateFrom(p.Context).loadBasket(p.Context)
+					if err != nil || basket == nil {
+						return nil, err
+					}
+					return basket, nil
+				},
+			},
+			"orders": &graphql.Field{
+				Type:        graphql.NewList(graphql.NewNonNull(orderType)),
+				Description: "Orders of the authenticated user",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return stateFrom(p.Context).loadOrders(p.Context)
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+func resolveProductBySlug(p graphql.ResolveParams) (interface{}, error) {
+	state := stateFrom(p.Context)
+	product, err := state.server.catalog.GetProductBySlug(p.Args["slug"].(string))
+	if err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := state.prepare([]*domain.Product{product}); err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+func resolveProducts(p graphql.ResolveParams) (interface{}, error) {
+	state := stateFrom(p.Context)
+	values := p.Args["ids"].([]interface{})
+	thunks := make([]func() (interface{}, error), 0, len(values))
+	for _, value := range values {
+		id, err := uuid.Parse(value.(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid UUID %q: %v", value, err)
+		}
+		thunks = append(thunks, state.products.Load(id))
+	}
+
+	return func() (interface{}, error) {
+		products := make([]interface{}, 0, len(thunks))
+		for _, thunk := range thunks {
+			product, err := thunk()
+			if err != nil {
+				return nil, err
+			}
+			products = append(products, product)
+		}
+		return products, nil
+	}, nil
+}
+
+// resolveRelated не группируется в пакет: рекомендации считаются для каждого товара отдельно,
+// их число ограничено сложностью запроса
+func resolveRelated(p graphql.ResolveParams) (interface{}, error) {
+	state := stateFrom(p.Context)
+	limit, _ := p.Args["limit"].(int)
+	products, err := state.server.recommendation.GetRelatedProducts(p.Source.(*domain.Product).ID.String(), limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := state.prepare(products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
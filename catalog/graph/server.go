@@ -0,0 +1,169 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/yangirxd/store-app/catalog/client"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/service"
+)
+
+var ErrUnauthenticated = errors.New("authentication required")
+
+// ProductOptions — параметры витрины из HTTP-запроса, общие для всех товаров ответа
+type ProductOptions struct {
+	Currency string
+	Market   string
+	Country  string
+	Region   string
+	Locales  []string // Предпочитаемые языки в порядке убывания
+}
+
+// Request — запрос GraphQL и данные HTTP-запроса, от имени которого он выполняется
+type Request struct {
+	Query         string
+	OperationName string
+	Variables     map[string]interface{}
+	Authorization string // Передается в basket и orders; пуст у анонимного запроса
+	Options       ProductOptions
+}
+
+// Server выполняет запросы витрины: товары читаются из сервисов каталога,
+// корзина и заказы — из сервисов basket и orders от имени пользователя
+type Server struct {
+	schema         graphql.Schema
+	catalog        *service.CatalogService
+	pricing        *service.PricingService
+	translation    *service.TranslationService
+	recommendation *service.RecommendationService
+	basket         client.BasketClient
+	orders         client.OrdersClient
+	maxDepth       int
+	maxComplexity  int
+}
+
+func NewServer(catalog *service.CatalogService, pricing *service.PricingService, translation *service.TranslationService, recommendation *service.RecommendationService, basket client.BasketClient, orders client.OrdersClient, maxDepth, maxComplexity int) (*Server, error) {
+	schema, err := newSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		schema:         schema,
+		catalog:        catalog,
+		pricing:        pricing,
+		translation:    translation,
+		recommendation: recommendation,
+		basket:         basket,
+		orders:         orders,
+		maxDepth:       maxDepth,
+		maxComplexity:  maxComplexity,
+	}, nil
+}
+
+// Execute выполняет запрос. false означает, что запрос отклонен до выполнения:
+// он не разобран, не прошел проверку схемы или превысил лимиты глубины и сложности.
+func (s *Server) Execute(ctx context.Context, req Request) (*graphql.Result, bool) {
+	document, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}, false
+	}
+
+	validation := graphql.ValidateDocument(&s.schema, document, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}, false
+	}
+	if err := checkLimits(&s.schema, document, req.OperationName, req.Variables, s.maxDepth, s.maxComplexity); err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}, false
+	}
+
+	state := &requestState{server: s, request: req}
+	state.products = newProductLoader(state.fetchProducts)
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           document,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       context.WithValue(ctx, requestStateKey{}, state),
+	}), true
+}
+
+type requestStateKey struct{}
+
+// requestState — данные одного запроса: загрузчик товаров и однажды прочитанные корзина и заказы
+type requestState struct {
+	server   *Server
+	request  Request
+	products *productLoader
+
+	basket       *client.Basket
+	basketLoaded bool
+	orders       []client.Order
+	ordersLoaded bool
+}
+
+func stateFrom(ctx context.Context) *requestState {
+	return ctx.Value(requestStateKey{}).(*requestState)
+}
+
+func (r *requestState) fetchProducts(ids []string) ([]*domain.Product, error) {
+	// GraphQL отдает витрину, поэтому только публичные товары
+	products, _, err := r.server.catalog.GetProductsByIDs(ids, domain.Actor{})
+	if err != nil {
+		return nil, err
+	}
+	if err := r.prepare(products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// prepare пересчитывает цены и переводит товары так же, как REST-ответы витрины
+func (r *requestState) prepare(products []*domain.Product) error {
+	options := r.request.Options
+	if err := r.server.pricing.ApplyPrices(products, options.Currency, options.Market); err != nil {
+		return err
+	}
+	if err := r.server.pricing.ApplyTaxes(products, options.Country, options.Region); err != nil {
+		return err
+	}
+	_, err := r.server.translation.Localize(products, options.Locales)
+	return err
+}
+
+func (r *requestState) loadBasket(ctx context.Context) (*client.Basket, error) {
+	if r.request.Authorization == "" {
+		return nil, ErrUnauthenticated
+	}
+	if !r.basketLoaded {
+		basket, err := r.server.basket.GetBasket(ctx, r.request.Authorization)
+		if err != nil {
+			return nil, err
+		}
+		r.basket, r.basketLoaded = basket, true
+	}
+
+	return r.basket, nil
+}
+
+func (r *requestState) loadOrders(ctx context.Context) ([]client.Order, error) {
+	if r.request.Authorization == "" {
+		return nil, ErrUnauthenticated
+	}
+	if !r.ordersLoaded {
+		orders, err := r.server.orders.GetOrders(ctx, r.request.Authorization)
+		if err != nil {
+			return nil, err
+		}
+		r.orders, r.ordersLoaded = orders, true
+	}
+
+	return r.orders, nil
+}
@@ -0,0 +1,112 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"github.com/segmentio/kafka-go"
+	"log"
+	"time"
+)
+
+const (
+	minRetryBackoff = time.Second
+	maxRetryBackoff = time.Minute
+)
+
+// ErrMalformedMessage оборачивает ошибку handler, которую повтор не исправит, например неразбираемый payload.
+// Такое сообщение пропускается, остальные ошибки повторяются, пока handler не справится.
+var ErrMalformedMessage = errors.New("malformed message")
+
+type Consumer struct {
+	reader *kafka.Reader
+}
+
+func NewConsumer(brokers []string, topic, groupID string) *Consumer {
+	log.Printf("Creating consumer for brokers: %v, topic: %s, groupID: %s", brokers, topic, groupID)
+	return newConsumer(kafka.ReaderConfig{
+		Brokers:  brokers,
+		Topic:    topic,
+		GroupID:  groupID,
+		MinBytes: 10e3,
+		MaxBytes: 10e6,
+	})
+}
+
+// NewTailConsumer читает только сообщения, появившиеся после первого подключения группы,
+// и отдает их без ожидания пачки. Подходит для групп одной реплики, например для сброса кэша:
+// новой реплике не нужна история топика, а событие должно дойти как можно быстрее.
+func NewTailConsumer(brokers []string, topic, groupID string) *Consumer {
+	log.Printf("Creating tail consumer for brokers: %v, topic: %s, groupID: %s", brokers, topic, groupID)
+	return newConsumer(kafka.ReaderConfig{
+		Brokers:     brokers,
+		Topic:       topic,
+		GroupID:     groupID,
+		StartOffset: kafka.LastOffset,
+		MinBytes:    1,
+		MaxBytes:    10e6,
+		MaxWait:     100 * time.Millisecond,
+	})
+}
+
+func newConsumer(config kafka.ReaderConfig) *Consumer {
+	return &Consumer{
+		reader: kafka.NewReader(config),
+	}
+}
+
+// Consume передает сообщения handler и фиксирует смещение только после обработки,
+// поэтому событие, которое не удалось применить (например, база недоступна), не теряется.
+func (c *Consumer) Consume(ctx context.Context, handler func([]byte) error) {
+	log.Printf("Starting to consume messages from topic: %s", c.reader.Config().Topic)
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				log.Printf("Context cancelled, stopping consumer: %v", ctx.Err())
+				return
+			}
+			log.Printf("Failed to fetch message: %v", err)
+			continue
+		}
+		// Тело сообщения не логируется: в заказах есть email покупателя
+		log.Printf("Received message %s/%d@%d key %q", msg.Topic, msg.Partition, msg.Offset, msg.Key)
+
+		if !c.process(ctx, msg, handler) {
+			log.Printf("Context cancelled, stopping consumer: %v", ctx.Err())
+			return
+		}
+		if err := c.reader.CommitMessages(ctx, msg); err != nil {
+			// Сообщение придет повторно; обработчики идемпотентны
+			log.Printf("Failed to commit message %s/%d@%d: %v", msg.Topic, msg.Partition, msg.Offset, err)
+		}
+	}
+}
+
+// process вызывает handler с растущей паузой между попытками, пока он не вернет nil
+// или ErrMalformedMessage. Возвращает false, если контекст отменен до успешной обработки.
+func (c *Consumer) process(ctx context.Context, msg kafka.Message, handler func([]byte) error) bool {
+	backoff := minRetryBackoff
+	for {
+		err := handler(msg.Value)
+		if err == nil {
+			return true
+		}
+		if errors.Is(err, ErrMalformedMessage) {
+			log.Printf("Skipping message %s/%d@%d: %v", msg.Topic, msg.Partition, msg.Offset, err)
+			return true
+		}
+
+		log.Printf("Failed to process message %s/%d@%d, retrying in %s: %v", msg.Topic, msg.Partition, msg.Offset, backoff, err)
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+func (c *Consumer) Close() error {
+	log.Printf("Closing consumer")
+	return c.reader.Close()
+}
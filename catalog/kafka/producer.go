@@ -0,0 +1,52 @@
+package kafka
+
+import (
+	"context"
+	"github.com/segmentio/kafka-go"
+	"time"
+)
+
+// Message — сообщение для пакетной публикации через ProduceMessages
+type Message struct {
+	Topic string
+	Key   []byte
+	Value []byte
+}
+
+type Producer struct {
+	writer *kafka.Writer
+}
+
+func NewProducer(brokers []string) *Producer {
+	return &Producer{
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Balancer: &kafka.Hash{},
+			// По умолчанию writer ждет пачку до секунды, и каждое событие задерживало бы запрос
+			BatchTimeout: 10 * time.Millisecond,
+		},
+	}
+}
+
+// Produce публикует сообщение с ключом: события одного товара попадают в одну партицию
+func (p *Producer) Produce(ctx context.Context, topic string, key, message []byte) error {
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Topic: topic,
+		Key:   key,
+		Value: message,
+	})
+}
+
+// ProduceMessages публикует сообщения одним запросом; сообщения разных топиков можно смешивать
+func (p *Producer) ProduceMessages(ctx context.Context, messages []Message) error {
+	batch := make([]kafka.Message, 0, len(messages))
+	for _, message := range messages {
+		batch = append(batch, kafka.Message{Topic: message.Topic, Key: message.Key, Value: message.Value})
+	}
+
+	return p.writer.WriteMessages(ctx, batch...)
+}
+
+func (p *Producer) Close() error {
+	return p.writer.Close()
+}
@@ -9,28 +9,83 @@ import (
 
 func CatalogMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header required"})
+		if !authenticateUser(c) {
 			c.Abort()
 			return
 		}
+		c.Next()
+	}
+}
+
+// ServiceOrUserMiddleware пропускает другие сервисы по заголовку X-Service-Token,
+// а остальных — по JWT, как CatalogMiddleware
+func ServiceOrUserMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := c.GetHeader("X-Service-Token"); token != "" {
+			if !domain.IsServiceToken(token) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid service token"})
+				c.Abort()
+				return
+			}
+			c.Set("isService", true)
+			c.Next()
+			return
+		}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token format"})
+		if !authenticateUser(c) {
 			c.Abort()
 			return
 		}
+		c.Next()
+	}
+}
 
-		claims, err := domain.ValidateJWT(tokenString)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+// OptionalUserMiddleware пропускает анонимные запросы, а запросы с заголовком Authorization
+// проверяет, как CatalogMiddleware
+func OptionalUserMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" && !authenticateUser(c) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// authenticateUser проверяет JWT и кладет email пользователя в контекст; при ошибке отвечает 401
+func authenticateUser(c *gin.Context) bool {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header required"})
+		return false
+	}
+
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	if tokenString == authHeader {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token format"})
+		return false
+	}
+
+	claims, err := domain.ValidateJWT(tokenString)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+		return false
+	}
+
+	c.Set("email", claims.Email)
+	c.Set("isAdmin", domain.IsAdmin(claims.Email))
+	return true
+}
+
+// AdminMiddleware пропускает только администраторов; ставится после CatalogMiddleware
+func AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !c.GetBool("isAdmin") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
 			c.Abort()
 			return
 		}
 
-		c.Set("email", claims.Email)
 		c.Next()
 	}
 }
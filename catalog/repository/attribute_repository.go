@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"github.com/yangirxd/store-app/catalog/domain"
+	"gorm.io/gorm"
+)
+
+type AttributeRepository interface {
+	Create(definition *domain.AttributeDefinition) error
+	FindAll() ([]*domain.AttributeDefinition, error)
+	Delete(code string) error
+	SetProductAttributes(product *domain.Product, attributes []domain.ProductAttribute) error
+	CountValues(filter domain.ProductFilter, code string) ([]domain.FacetValue, error)
+}
+
+type PostgresAttributeRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresAttributeRepository(db *gorm.DB) *PostgresAttributeRepository {
+	return &PostgresAttributeRepository{db: db}
+}
+
+func (r *PostgresAttributeRepository) Create(definition *domain.AttributeDefinition) error {
+	var count int64
+	if err := r.db.Model(&domain.AttributeDefinition{}).Where("code = ?", definition.Code).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return domain.ErrAttributeExists
+	}
+
+	return r.db.Create(definition).Error
+}
+
+func (r *PostgresAttributeRepository) FindAll() ([]*domain.AttributeDefinition, error) {
+	var definitions []*domain.AttributeDefinition
+	if err := r.db.Order("code").Find(&definitions).Error; err != nil {
+		return nil, err
+	}
+
+	return definitions, nil
+}
+
+// Delete удаляет характеристику вместе с ее значениями у всех товаров
+func (r *PostgresAttributeRepository) Delete(code string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("code = ?", code).Delete(&domain.AttributeDefinition{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrAttributeNotFound
+		}
+
+		return tx.Where("code = ?", code).Delete(&domain.ProductAttribute{}).Error
+	})
+}
+
+// SetProductAttributes заменяет все значения характеристик товара и увеличивает его версию
+func (r *PostgresAttributeRepository) SetProductAttributes(product *domain.Product, attributes []domain.ProductAttribute) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Product{}).
+			Where("id = ? AND version = ?", product.ID, product.Version).
+			Update("version", gorm.Expr("version + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return productConflictOrNotFound(tx, product.ID)
+		}
+
+		if err := tx.Where("product_id = ?", product.ID).Delete(&domain.ProductAttribute{}).Error; err != nil {
+			return err
+		}
+		if len(attributes) > 0 {
+			if err := tx.Create(&attributes).Error; err != nil {
+				return err
+			}
+		}
+
+		product.Attributes = attributes
+		product.Version++
+		return nil
+	})
+}
+
+// CountValues считает товары, подходящие под фильтр, по каждому значению характеристики code
+func (r *PostgresAttributeRepository) CountValues(filter domain.ProductFilter, code string) ([]domain.FacetValue, error) {
+	products := applyProductFilter(r.db.Model(&domain.Product{}), filter).Select("products.id")
+
+	var values []domain.FacetValue
+	if err := r.db.Model(&domain.ProductAttribute{}).
+		Select("value, COUNT(*) AS count").
+		Where("code = ? AND product_id IN (?)", code, products).
+		Group("value").
+		Order("count DESC, value").
+		Scan(&values).Error; err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
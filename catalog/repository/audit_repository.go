@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"gorm.io/gorm"
+)
+
+type AuditRepository interface {
+	Append(record *domain.AuditRecord) error
+	Find(filter domain.AuditFilter, limit int) ([]*domain.AuditRecord, error)
+}
+
+type PostgresAuditRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresAuditRepository(db *gorm.DB) *PostgresAuditRepository {
+	return &PostgresAuditRepository{db: db}
+}
+
+func (r *PostgresAuditRepository) Append(record *domain.AuditRecord) error {
+	return r.db.Create(record).Error
+}
+
+// Find возвращает записи журнала, новые первыми
+func (r *PostgresAuditRepository) Find(filter domain.AuditFilter, limit int) ([]*domain.AuditRecord, error) {
+	query := r.db.Model(&domain.AuditRecord{})
+	if filter.ProductID != uuid.Nil {
+		query = query.Where("product_id = ?", filter.ProductID)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+
+	var records []*domain.AuditRecord
+	if err := query.Order("created_at DESC").Limit(limit).Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type BundleRepository interface {
+	SetComponents(product *domain.Product, components []domain.BundleComponent) error
+	FindComponents(productIDs []uuid.UUID) (map[uuid.UUID][]domain.BundleComponent, error)
+	FindAvailability(bundleIDs []uuid.UUID) (map[uuid.UUID]int, error)
+	SaveOrderComponents(snapshot []domain.OrderComponent) ([]domain.OrderComponent, error)
+	FindOrderComponents(orderID uuid.UUID) ([]domain.OrderComponent, error)
+}
+
+type PostgresBundleRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresBundleRepository(db *gorm.DB) *PostgresBundleRepository {
+	return &PostgresBundleRepository{db: db}
+}
+
+// SetComponents заменяет состав набора и увеличивает версию товара.
+// Непустой состав делает товар набором, пустой — снова обычным товаром.
+func (r *PostgresBundleRepository) SetComponents(product *domain.Product, components []domain.BundleComponent) error {
+	now := time.Now()
+	productType := domain.ProductSimple
+	if len(components) > 0 {
+		productType = domain.ProductBundle
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var current domain.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND version = ?", product.ID, product.Version).
+			First(&current).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return productConflictOrNotFound(tx, product.ID)
+			}
+			return err
+		}
+
+		if productType == domain.ProductBundle {
+			if err := checkComponents(tx, &current, components); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("bundle_id = ?", product.ID).Delete(&domain.BundleComponent{}).Error; err != nil {
+			return err
+		}
+		if len(components) > 0 {
+			if err := tx.Create(&components).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&domain.Product{}).
+			Where("id = ?", product.ID).
+			Updates(map[string]interface{}{
+				"type":       productType,
+				"version":    gorm.Expr("version + 1"),
+				"updated_at": now,
+			}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	product.Type = productType
+	product.Components = components
+	product.Version++
+	product.UpdatedAt = now
+	return nil
+}
+
+// checkComponents проверяет, что товар может стать набором из components.
+// Вложенные наборы не поддерживаются: компонент не может быть набором, а набор — компонентом.
+func checkComponents(tx *gorm.DB, bundle *domain.Product, components []domain.BundleComponent) error {
+	if bundle.Stock != 0 {
+		return fmt.Errorf("%w: product has stock of its own, write it off before turning it into a bundle", domain.ErrInvalidBundle)
+	}
+
+	var usages int64
+	if err := tx.Model(&domain.BundleComponent{}).Where("product_id = ?", bundle.ID).Count(&usages).Error; err != nil {
+		return err
+	}
+	if usages > 0 {
+		return fmt.Errorf("%w: product is a component of another bundle", domain.ErrInvalidBundle)
+	}
+
+	ids := make([]uuid.UUID, 0, len(components))
+	for _, component := range components {
+		ids = append(ids, component.ProductID)
+	}
+	// Разделяемая блокировка не дает компоненту одновременно превратиться в набор
+	var products []*domain.Product
+	if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return err
+	}
+	found := make(map[uuid.UUID]*domain.Product, len(products))
+	for _, product := range products {
+		found[product.ID] = product
+	}
+	for _, id := range ids {
+		component, ok := found[id]
+		if !ok {
+			return fmt.Errorf("%w: component %s not found", domain.ErrInvalidBundle, id)
+		}
+		if component.IsBundle() {
+			return fmt.Errorf("%w: component %s is a bundle itself", domain.ErrInvalidBundle, id)
+		}
+	}
+
+	return nil
+}
+
+// FindComponents возвращает состав тех товаров из productIDs, которые являются наборами
+func (r *PostgresBundleRepository) FindComponents(productIDs []uuid.UUID) (map[uuid.UUID][]domain.BundleComponent, error) {
+	var components []domain.BundleComponent
+	if err := r.db.Where("bundle_id IN ?", productIDs).Order("bundle_id, product_id").Find(&components).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[uuid.UUID][]domain.BundleComponent)
+	for _, component := range components {
+		result[component.BundleID] = append(result[component.BundleID], component)
+	}
+
+	return result, nil
+}
+
+// FindAvailability считает, сколько наборов можно собрать из остатков компонентов.
+// Удаленный или снятый с продажи компонент делает набор недоступным.
+func (r *PostgresBundleRepository) FindAvailability(bundleIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	var rows []struct {
+		BundleID uuid.UUID
+		Stock    int
+	}
+	if err := r.db.Raw(`SELECT bc.bundle_id, MIN(COALESCE(p.stock, 0) / bc.quantity) AS stock
+		FROM bundle_components bc
+		LEFT JOIN products p ON p.id = bc.product_id AND p.deleted_at IS NULL AND p.status = ?
+		WHERE bc.bundle_id IN ?
+		GROUP BY bc.bundle_id`, domain.ProductActive, bundleIDs).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	availability := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		availability[row.BundleID] = row.Stock
+	}
+
+	return availability, nil
+}
+
+// SaveOrderComponents сохраняет снимок состава заказа, если его еще нет, и возвращает сохраненный.
+// Снимок пишут несколько подписчиков orders.created, поэтому повторная запись ничего не меняет.
+func (r *PostgresBundleRepository) SaveOrderComponents(snapshot []domain.OrderComponent) ([]domain.OrderComponent, error) {
+	if len(snapshot) == 0 {
+		return snapshot, nil
+	}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&snapshot).Error; err != nil {
+		return nil, err
+	}
+
+	return r.FindOrderComponents(snapshot[0].OrderID)
+}
+
+func (r *PostgresBundleRepository) FindOrderComponents(orderID uuid.UUID) ([]domain.OrderComponent, error) {
+	var snapshot []domain.OrderComponent
+	if err := r.db.Where("order_id = ?", orderID).Order("product_id, component_id").Find(&snapshot).Error; err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
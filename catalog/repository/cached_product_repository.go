@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// CachedProductRepository — декоратор ProductRepository с LRU-кэшем чтений.
+// Записи через декоратор сбрасывают кэш сразу. Изменения через другие репозитории (резервы, отзывы)
+// сбрасывает EventPublisher при публикации product.*, изменения из других реплик — CacheInvalidator.
+type CachedProductRepository struct {
+	next     ProductRepository
+	products *lruCache[uuid.UUID, *domain.Product]
+	lists    *lruCache[string, []*domain.Product]
+	// slugs не нужно сбрасывать: slug, текущий или прежний, навсегда закреплен за одним товаром
+	slugs *lruCache[string, uuid.UUID]
+	// generation растет при каждом сбросе: результат чтения, начатого до сброса, в кэш не попадает
+	generation atomic.Uint64
+}
+
+func NewCachedProductRepository(next ProductRepository, capacity int, ttl time.Duration) *CachedProductRepository {
+	return &CachedProductRepository{
+		next:     next,
+		products: newLRUCache[uuid.UUID, *domain.Product](capacity, ttl),
+		lists:    newLRUCache[string, []*domain.Product](capacity, ttl),
+		slugs:    newLRUCache[string, uuid.UUID](capacity, ttl),
+	}
+}
+
+func (r *CachedProductRepository) Create(product *domain.Product) error {
+	if err := r.next.Create(product); err != nil {
+		return err
+	}
+	r.invalidateLists()
+
+	return nil
+}
+
+func (r *CachedProductRepository) FindByID(id uuid.UUID) (*domain.Product, error) {
+	if product, ok := r.products.Get(id); ok {
+		return cloneProduct(product), nil
+	}
+
+	generation := r.generation.Load()
+	product, err := r.next.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	r.setProduct(generation, product)
+
+	return product, nil
+}
+
+func (r *CachedProductRepository) FindBySKU(sku string) (*domain.Product, error) {
+	return r.next.FindBySKU(sku)
+}
+
+// FindBySlug запоминает, какому товару принадлежит slug, а сам товар берет из кэша по ID
+func (r *CachedProductRepository) FindBySlug(slug string) (*domain.Product, error) {
+	if id, ok := r.slugs.Get(slug); ok {
+		return r.FindByID(id)
+	}
+
+	product, err := r.next.FindBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+	r.slugs.Set(slug, product.ID)
+
+	return product, nil
+}
+
+func (r *CachedProductRepository) FindAll(filter domain.ProductFilter) ([]*domain.Product, error) {
+	key := filterKey(filter)
+	if products, ok := r.lists.Get(key); ok {
+		return cloneProducts(products), nil
+	}
+
+	generation := r.generation.Load()
+	products, err := r.next.FindAll(filter)
+	if err != nil {
+		return nil, err
+	}
+	if r.generation.Load() == generation {
+		r.lists.Set(key, cloneProducts(products))
+	}
+
+	return products, nil
+}
+
+// FindByIDs берет из кэша найденные товары и запрашивает у базы только остальные
+func (r *CachedProductRepository) FindByIDs(ids []uuid.UUID, filter domain.ProductFilter) ([]*domain.Product, error) {
+	products := make([]*domain.Product, 0, len(ids))
+	var misses []uuid.UUID
+	for _, id := range ids {
+		product, ok := r.products.Get(id)
+		if !ok {
+			misses = append(misses, id)
+			continue
+		}
+		if matchesFilter(product, filter) {
+			products = append(products, cloneProduct(product))
+		}
+	}
+	if len(misses) == 0 {
+		return products, nil
+	}
+
+	found, err := r.next.FindByIDs(misses, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(products, found...), nil
+}
+
+func (r *CachedProductRepository) FindInBatches(filter domain.ProductFilter, batchSize int, fn func(products []*domain.Product) error) error {
+	return r.next.FindInBatches(filter, batchSize, fn)
+}
+
+func (r *CachedProductRepository) Update(product *domain.Product) error {
+	// Конфликт версий тоже сбрасывает кэш: закэшированная версия могла устареть
+	defer r.Invalidate(product.ID)
+	return r.next.Update(product)
+}
+
+func (r *CachedProductRepository) Delete(id uuid.UUID, version int) error {
+	defer r.Invalidate(id)
+	return r.next.Delete(id, version)
+}
+
+func (r *CachedProductRepository) Restore(id uuid.UUID) (*domain.Product, error) {
+	r.Invalidate(id)
+	return r.next.Restore(id)
+}
+
+// Invalidate сбрасывает товар и все закэшированные списки
+func (r *CachedProductRepository) Invalidate(id uuid.UUID) {
+	r.generation.Add(1)
+	r.products.Delete(id)
+	r.lists.Clear()
+}
+
+func (r *CachedProductRepository) invalidateLists() {
+	r.generation.Add(1)
+	r.lists.Clear()
+}
+
+func (r *CachedProductRepository) setProduct(generation uint64, product *domain.Product) {
+	if r.generation.Load() == generation {
+		r.products.Set(product.ID, cloneProduct(product))
+	}
+}
+
+// matchesFilter повторяет для закэшированного товара условия по статусу, удалению и продавцу.
+// Условия по характеристикам пакетный запрос не использует.
+func matchesFilter(product *domain.Product, filter domain.ProductFilter) bool {
+	if product.DeletedAt.Valid && !filter.IncludeDeleted {
+		return false
+	}
+	if filter.SellerID != uuid.Nil && (product.SellerID == nil || *product.SellerID != filter.SellerID) {
+		return false
+	}
+	if len(filter.Statuses) == 0 {
+		return true
+	}
+	for _, status := range filter.Statuses {
+		if product.Status == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+func filterKey(filter domain.ProductFilter) string {
+	var key strings.Builder
+	fmt.Fprintf(&key, "statuses=%v;deleted=%t;seller=%s", filter.Statuses, filter.IncludeDeleted, filter.SellerID)
+
+	codes := make([]string, 0, len(filter.Attributes))
+	for code := range filter.Attributes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		values := append([]string(nil), filter.Attributes[code]...)
+		sort.Strings(values)
+		fmt.Fprintf(&key, ";attr[%s]=%s", code, strings.Join(values, ","))
+	}
+
+	return key.String()
+}
+
+// cloneProduct копирует товар: вызывающий код меняет полученные товары (например, пересчитывает цену)
+func cloneProduct(product *domain.Product) *domain.Product {
+	clone := *product
+	clone.Attributes = append([]domain.ProductAttribute(nil), product.Attributes...)
+	clone.Media = append([]domain.ProductMedia(nil), product.Media...)
+	clone.Components = append([]domain.BundleComponent(nil), product.Components...)
+	return &clone
+}
+
+func cloneProducts(products []*domain.Product) []*domain.Product {
+	clones := make([]*domain.Product, 0, len(products))
+	for _, product := range products {
+		clones = append(clones, cloneProduct(product))
+	}
+
+	return clones
+}
@@ -1,17 +1,25 @@
 package repository
 
 import (
+	"errors"
 	"github.com/google/uuid"
 	"github.com/yangirxd/store-app/catalog/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
 )
 
 type ProductRepository interface {
 	Create(product *domain.Product) error
 	FindByID(id uuid.UUID) (*domain.Product, error)
-	FindAll() ([]*domain.Product, error)
+	FindBySKU(sku string) (*domain.Product, error)
+	FindBySlug(slug string) (*domain.Product, error)
+	FindAll(filter domain.ProductFilter) ([]*domain.Product, error)
+	FindByIDs(ids []uuid.UUID, filter domain.ProductFilter) ([]*domain.Product, error)
+	FindInBatches(filter domain.ProductFilter, batchSize int, fn func(products []*domain.Product) error) error
 	Update(product *domain.Product) error
-	Delete(id uuid.UUID) error
+	Delete(id uuid.UUID, version int) error
+	Restore(id uuid.UUID) (*domain.Product, error)
 }
 
 type PostgresProductRepository struct {
@@ -22,32 +30,308 @@ func NewPostgresProductRepository(db *gorm.DB) *PostgresProductRepository {
 	return &PostgresProductRepository{db: db}
 }
 
+// Create сохраняет товар; начальный остаток поступает на основной склад.
+// Если slug не задан, он строится из названия.
 func (r *PostgresProductRepository) Create(product *domain.Product) error {
-	return r.db.Create(product).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if product.Slug == "" {
+			slug, err := uniqueSlug(tx, product.Name, product.ID)
+			if err != nil {
+				return err
+			}
+			product.Slug = slug
+		}
+		if err := tx.Create(product).Error; err != nil {
+			return err
+		}
+
+		return moveStock(tx, product.ID, product.Stock, domain.AdjustmentCatalogEdit, "")
+	})
 }
 
 func (r *PostgresProductRepository) FindByID(id uuid.UUID) (*domain.Product, error) {
 	var product domain.Product
-	if err := r.db.Where("id = ?", id).First(&product).Error; err != nil {
+	if err := preloadDetails(r.db).Where("id = ?", id).First(&product).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// FindBySKU ищет товар и среди удаленных: уникальный индекс SKU покрывает и их,
+// поэтому артикул удаленного товара занят, пока товар не восстановят
+func (r *PostgresProductRepository) FindBySKU(sku string) (*domain.Product, error) {
+	var product domain.Product
+	if err := preloadDetails(r.db.Unscoped()).Where("sku = ?", sku).First(&product).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrProductNotFound
+		}
 		return nil, err
 	}
 
 	return &product, nil
 }
 
-func (r *PostgresProductRepository) FindAll() ([]*domain.Product, error) {
+// FindBySlug ищет товар по текущему или прежнему slug. Вызывающий код сравнивает
+// product.Slug с запрошенным, чтобы отличить прежний slug.
+func (r *PostgresProductRepository) FindBySlug(slug string) (*domain.Product, error) {
+	var product domain.Product
+	err := preloadDetails(r.db).
+		Where("slug = ? OR id = (SELECT product_id FROM product_slugs WHERE slug = ?)", slug, slug).
+		First(&product).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+func (r *PostgresProductRepository) FindAll(filter domain.ProductFilter) ([]*domain.Product, error) {
+	var products []*domain.Product
+	if err := applyProductFilter(preloadDetails(r.db), filter).Find(&products).Error; err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// FindByIDs возвращает найденные товары одним запросом, без характеристик и изображений
+func (r *PostgresProductRepository) FindByIDs(ids []uuid.UUID, filter domain.ProductFilter) ([]*domain.Product, error) {
 	var products []*domain.Product
-	if err := r.db.Find(&products).Error; err != nil {
+	if err := applyProductFilter(r.db, filter).Where("products.id IN ?", ids).Find(&products).Error; err != nil {
 		return nil, err
 	}
 
 	return products, nil
 }
 
+// FindInBatches читает товары порциями, не загружая весь каталог в память
+func (r *PostgresProductRepository) FindInBatches(filter domain.ProductFilter, batchSize int, fn func(products []*domain.Product) error) error {
+	var products []*domain.Product
+	return applyProductFilter(r.db, filter).
+		Order("id").
+		FindInBatches(&products, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(products)
+		}).Error
+}
+
+func applyProductFilter(db *gorm.DB, filter domain.ProductFilter) *gorm.DB {
+	if filter.IncludeDeleted {
+		db = db.Unscoped()
+	}
+	if len(filter.Statuses) > 0 {
+		db = db.Where("products.status IN ?", filter.Statuses)
+	}
+	if filter.SellerID != uuid.Nil {
+		db = db.Where("products.seller_id = ?", filter.SellerID)
+	}
+	for code, values := range filter.Attributes {
+		db = db.Where(`EXISTS (SELECT 1 FROM product_attributes pa
+			WHERE pa.product_id = products.id AND pa.code = ? AND pa.value IN ?)`, code, values)
+	}
+
+	return db
+}
+
+// preloadDetails подгружает характеристики, изображения и состав набора
+func preloadDetails(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Attributes", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("code")
+		}).
+		Preload("Media", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("position")
+		}).
+		Preload("Components", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("product_id")
+		})
+}
+
+// Update сохраняет товар, только если его версия в базе совпадает с product.Version,
+// и увеличивает версию. Иначе возвращает domain.ErrVersionConflict.
+// При смене названия меняется slug, прежний остается в истории.
+// Изменение остатка распределяется по складам так же, как приход и расход по заказам.
+// Остаток набора не хранится, поэтому для набора он не меняется.
 func (r *PostgresProductRepository) Update(product *domain.Product) error {
-	return r.db.Save(product).Error
+	now := time.Now()
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var current domain.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND version = ?", product.ID, product.Version).
+			First(&current).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return productConflictOrNotFound(tx, product.ID)
+			}
+			return err
+		}
+		if current.IsBundle() {
+			product.Stock = current.Stock
+		}
+
+		updates := map[string]interface{}{
+			"sku":               product.SKU,
+			"name":              product.Name,
+			"description":       product.Description,
+			"price":             product.Price,
+			"stock":             product.Stock,
+			"status":            product.Status,
+			"tax_class":         product.TaxClass,
+			"reorder_threshold": product.ReorderThreshold,
+			"target_stock":      product.TargetStock,
+			"version":           gorm.Expr("version + 1"),
+			"updated_at":        now,
+		}
+		if product.Name != current.Name {
+			slug, err := renameSlug(tx, &current, product.Name)
+			if err != nil {
+				return err
+			}
+			product.Slug = slug
+			updates["slug"] = slug
+		}
+		if err := tx.Model(&domain.Product{}).Where("id = ?", product.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		return moveStock(tx, product.ID, product.Stock-current.Stock, domain.AdjustmentCatalogEdit, "")
+	})
+	if err != nil {
+		return err
+	}
+
+	product.Version++
+	product.UpdatedAt = now
+	return nil
 }
 
-func (r *PostgresProductRepository) Delete(id uuid.UUID) error {
-	return r.db.Where("id = ?", id).Delete(&domain.Product{}).Error
+// Delete мягко удаляет товар: строка остается, чтобы не ломать ссылки из заказов
+func (r *PostgresProductRepository) Delete(id uuid.UUID, version int) error {
+	result := r.db.Where("id = ? AND version = ?", id, version).Delete(&domain.Product{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return r.conflictOrNotFound(id)
+	}
+
+	return nil
+}
+
+// Restore возвращает мягко удаленный товар в каталог
+func (r *PostgresProductRepository) Restore(id uuid.UUID) (*domain.Product, error) {
+	result := r.db.Unscoped().Model(&domain.Product{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, domain.ErrProductNotFound
+	}
+
+	return r.FindByID(id)
+}
+
+func (r *PostgresProductRepository) conflictOrNotFound(id uuid.UUID) error {
+	return productConflictOrNotFound(r.db, id)
+}
+
+// productConflictOrNotFound объясняет, почему условное изменение товара не затронуло строк
+func productConflictOrNotFound(db *gorm.DB, id uuid.UUID) error {
+	var count int64
+	if err := db.Model(&domain.Product{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return domain.ErrProductNotFound
+	}
+
+	return domain.ErrVersionConflict
+}
+
+// AssignMissingSlugs присваивает slug товарам, созданным до появления slug
+func (r *PostgresProductRepository) AssignMissingSlugs() (int, error) {
+	var products []*domain.Product
+	if err := r.db.Unscoped().Select("id", "name").Where("slug IS NULL OR slug = ''").Find(&products).Error; err != nil {
+		return 0, err
+	}
+
+	for i, product := range products {
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			slug, err := uniqueSlug(tx, product.Name, product.ID)
+			if err != nil {
+				return err
+			}
+			return tx.Unscoped().Model(&domain.Product{}).Where("id = ?", product.ID).UpdateColumn("slug", slug).Error
+		})
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return len(products), nil
+}
+
+// uniqueSlug строит slug из названия и при совпадении добавляет номер.
+// Прежние slug других товаров тоже заняты, иначе их перенаправления указали бы на чужой товар.
+func uniqueSlug(tx *gorm.DB, name string, productID uuid.UUID) (string, error) {
+	base := domain.Slugify(name)
+	// В slug только латиница, цифры и дефисы, поэтому экранировать шаблон LIKE не нужно
+	pattern := base + "-%"
+
+	var taken []string
+	if err := tx.Unscoped().Model(&domain.Product{}).
+		Where("id <> ? AND (slug = ? OR slug LIKE ?)", productID, base, pattern).
+		Pluck("slug", &taken).Error; err != nil {
+		return "", err
+	}
+	var history []string
+	if err := tx.Model(&domain.ProductSlug{}).
+		Where("product_id <> ? AND (slug = ? OR slug LIKE ?)", productID, base, pattern).
+		Pluck("slug", &history).Error; err != nil {
+		return "", err
+	}
+
+	used := make(map[string]bool, len(taken)+len(history))
+	for _, slug := range append(taken, history...) {
+		used[slug] = true
+	}
+	for n := 1; ; n++ {
+		if slug := domain.SlugWithSuffix(base, n); !used[slug] {
+			return slug, nil
+		}
+	}
+}
+
+// renameSlug подбирает slug для нового названия и сохраняет текущий slug в истории
+func renameSlug(tx *gorm.DB, current *domain.Product, name string) (string, error) {
+	slug, err := uniqueSlug(tx, name, current.ID)
+	if err != nil || slug == current.Slug {
+		return slug, err
+	}
+
+	if current.Slug != "" {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&domain.ProductSlug{
+			Slug:      current.Slug,
+			ProductID: current.ID,
+			CreatedAt: time.Now(),
+		}).Error; err != nil {
+			return "", err
+		}
+	}
+	// Товару вернули прежнее название: slug снова текущий и из истории уходит
+	if err := tx.Where("slug = ?", slug).Delete(&domain.ProductSlug{}).Error; err != nil {
+		return "", err
+	}
+
+	return slug, nil
 }
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"gorm.io/gorm"
+)
+
+type ImportJobRepository interface {
+	Create(job *domain.ImportJob) error
+	Update(job *domain.ImportJob) error
+	FindByID(id uuid.UUID) (*domain.ImportJob, error)
+}
+
+type PostgresImportJobRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresImportJobRepository(db *gorm.DB) *PostgresImportJobRepository {
+	return &PostgresImportJobRepository{db: db}
+}
+
+func (r *PostgresImportJobRepository) Create(job *domain.ImportJob) error {
+	return r.db.Omit("Errors").Create(job).Error
+}
+
+// Update сохраняет счетчики задания; новые ошибки строк добавляются, уже сохраненные пропускаются
+func (r *PostgresImportJobRepository) Update(job *domain.ImportJob) error {
+	return r.db.Save(job).Error
+}
+
+func (r *PostgresImportJobRepository) FindByID(id uuid.UUID) (*domain.ImportJob, error) {
+	var job domain.ImportJob
+	if err := r.db.Preload("Errors", func(db *gorm.DB) *gorm.DB {
+		return db.Order("row")
+	}).Where("id = ?", id).First(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrImportJobNotFound
+		}
+		return nil, err
+	}
+
+	return &job, nil
+}
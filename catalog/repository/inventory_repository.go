@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"errors"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sort"
+	"time"
+)
+
+type InventoryRepository interface {
+	ApplyStockEvent(event *domain.StockEvent) ([]domain.StockChange, error)
+}
+
+type PostgresInventoryRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresInventoryRepository(db *gorm.DB) *PostgresInventoryRepository {
+	return &PostgresInventoryRepository{db: db}
+}
+
+// ApplyStockEvent изменяет остатки и отмечает событие обработанным в одной транзакции.
+// Повторное событие возвращает domain.ErrEventAlreadyProcessed без изменения остатков.
+func (r *PostgresInventoryRepository) ApplyStockEvent(event *domain.StockEvent) ([]domain.StockChange, error) {
+	var changes []domain.StockChange
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&domain.ProcessedEvent{
+			EventID:     event.EventID,
+			Topic:       event.Topic,
+			ProcessedAt: time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrEventAlreadyProcessed
+		}
+
+		if event.ReservationReferenceID != "" {
+			committed, err := commitReservationIfExists(tx, event.ReservationReferenceID)
+			if err != nil || committed {
+				return err
+			}
+		}
+
+		deltas := append([]domain.StockDelta(nil), event.Deltas...)
+		sort.Slice(deltas, func(i, j int) bool {
+			return deltas[i].ProductID.String() < deltas[j].ProductID.String()
+		})
+
+		for _, delta := range deltas {
+			var product domain.Product
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", delta.ProductID).
+				First(&product).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					// Товар мог быть удален после оформления заказа
+					continue
+				}
+				return err
+			}
+
+			after := product.Stock + delta.Delta
+			if after < 0 {
+				after = 0
+			}
+			if err := tx.Model(&domain.Product{}).
+				Where("id = ?", product.ID).
+				Updates(map[string]interface{}{
+					"stock":   after,
+					"version": gorm.Expr("version + 1"),
+				}).Error; err != nil {
+				return err
+			}
+			if err := moveStock(tx, product.ID, after-product.Stock, domain.StockAdjustmentReasonFor(event.Topic), event.EventID); err != nil {
+				return err
+			}
+			changes = append(changes, domain.StockChange{
+				ProductID: product.ID,
+				Before:    product.Stock,
+				After:     after,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// commitReservationIfExists подтверждает резерв по ссылке; true означает, что остатки уже списаны резервом
+func commitReservationIfExists(tx *gorm.DB, referenceID string) (bool, error) {
+	var reservation domain.Reservation
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("reference_id = ?", referenceID).
+		First(&reservation).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	switch reservation.Status {
+	case domain.ReservationActive:
+		return true, updateReservationStatus(tx, &reservation, domain.ReservationCommitted)
+	case domain.ReservationCommitted:
+		return true, nil
+	default:
+		// Резерв истек и остатки возвращены — списываем по событию
+		return false, nil
+	}
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lruCache — потокобезопасный LRU-кэш с ограниченным временем жизни записей
+type lruCache[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	items    map[K]*list.Element
+	order    *list.List // В начале — недавно использованные записи
+}
+
+type lruEntry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+func newLRUCache[K comparable, V any](capacity int, ttl time.Duration) *lruCache[K, V] {
+	return &lruCache[K, V]{
+		capacity: capacity,
+		ttl:      ttl,
+		items:    make(map[K]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *lruCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	element, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+	entry := element.Value.(*lruEntry[K, V])
+	if time.Now().After(entry.expiresAt) {
+		c.removeElement(element)
+		return zero, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+func (c *lruCache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(c.ttl)
+	if element, ok := c.items[key]; ok {
+		entry := element.Value.(*lruEntry[K, V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+	}
+}
+
+func (c *lruCache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		c.removeElement(element)
+	}
+}
+
+func (c *lruCache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[K]*list.Element)
+	c.order.Init()
+}
+
+func (c *lruCache[K, V]) removeElement(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.items, element.Value.(*lruEntry[K, V]).key)
+}
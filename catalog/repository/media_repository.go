@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"gorm.io/gorm"
+)
+
+type MediaRepository interface {
+	Create(media *domain.ProductMedia) error
+	FindByID(productID, id uuid.UUID) (*domain.ProductMedia, error)
+	FindByProductID(productID uuid.UUID) ([]*domain.ProductMedia, error)
+	Delete(media *domain.ProductMedia) error
+	Reorder(productID uuid.UUID, ids []uuid.UUID) error
+}
+
+type PostgresMediaRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresMediaRepository(db *gorm.DB) *PostgresMediaRepository {
+	return &PostgresMediaRepository{db: db}
+}
+
+// Create добавляет изображение в конец списка товара и увеличивает версию товара
+func (r *PostgresMediaRepository) Create(media *domain.ProductMedia) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpProductVersion(tx, media.ProductID); err != nil {
+			return err
+		}
+
+		var position int
+		if err := tx.Model(&domain.ProductMedia{}).
+			Where("product_id = ?", media.ProductID).
+			Select("COALESCE(MAX(position) + 1, 0)").
+			Scan(&position).Error; err != nil {
+			return err
+		}
+		media.Position = position
+
+		return tx.Create(media).Error
+	})
+}
+
+func (r *PostgresMediaRepository) FindByID(productID, id uuid.UUID) (*domain.ProductMedia, error) {
+	var media domain.ProductMedia
+	if err := r.db.Where("id = ? AND product_id = ?", id, productID).First(&media).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrMediaNotFound
+		}
+		return nil, err
+	}
+
+	return &media, nil
+}
+
+func (r *PostgresMediaRepository) FindByProductID(productID uuid.UUID) ([]*domain.ProductMedia, error) {
+	var media []*domain.ProductMedia
+	if err := r.db.Where("product_id = ?", productID).Order("position").Find(&media).Error; err != nil {
+		return nil, err
+	}
+
+	return media, nil
+}
+
+func (r *PostgresMediaRepository) Delete(media *domain.ProductMedia) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&domain.ProductMedia{}, "id = ?", media.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrMediaNotFound
+		}
+
+		return bumpProductVersion(tx, media.ProductID)
+	})
+}
+
+// Reorder задает порядок изображений; ids должен содержать все изображения товара
+func (r *PostgresMediaRepository) Reorder(productID uuid.UUID, ids []uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing []uuid.UUID
+		if err := tx.Model(&domain.ProductMedia{}).
+			Where("product_id = ?", productID).
+			Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+
+		known := make(map[uuid.UUID]bool, len(existing))
+		for _, id := range existing {
+			known[id] = true
+		}
+		if len(ids) != len(existing) {
+			return fmt.Errorf("%w: order must list all %d media of the product", domain.ErrInvalidMediaOrder, len(existing))
+		}
+		for position, id := range ids {
+			if !known[id] {
+				return fmt.Errorf("%w: unknown or repeated media %s", domain.ErrInvalidMediaOrder, id)
+			}
+			delete(known, id)
+			if err := tx.Model(&domain.ProductMedia{}).
+				Where("id = ?", id).
+				Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+
+		return bumpProductVersion(tx, productID)
+	})
+}
+
+// bumpProductVersion увеличивает версию товара, чтобы изменение связанных данных меняло ETag
+func bumpProductVersion(tx *gorm.DB, productID uuid.UUID) error {
+	result := tx.Model(&domain.Product{}).
+		Where("id = ?", productID).
+		Update("version", gorm.Expr("version + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrProductNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"gorm.io/gorm"
+	"time"
+)
+
+type PriceHistoryRepository interface {
+	Append(entry *domain.PriceHistory) error
+	FindByProductID(productID uuid.UUID, limit int) ([]*domain.PriceHistory, error)
+}
+
+type PriceScheduleRepository interface {
+	Create(schedule *domain.PriceSchedule) error
+	FindByID(id uuid.UUID) (*domain.PriceSchedule, error)
+	FindByProductID(productID uuid.UUID) ([]*domain.PriceSchedule, error)
+	FindOpenByProductID(productID uuid.UUID) ([]*domain.PriceSchedule, error)
+	FindDue(now time.Time, limit int) ([]*domain.PriceSchedule, error)
+	Transition(schedule *domain.PriceSchedule, from domain.PriceScheduleStatus) (bool, error)
+}
+
+type PostgresPriceHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresPriceHistoryRepository(db *gorm.DB) *PostgresPriceHistoryRepository {
+	return &PostgresPriceHistoryRepository{db: db}
+}
+
+func (r *PostgresPriceHistoryRepository) Append(entry *domain.PriceHistory) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *PostgresPriceHistoryRepository) FindByProductID(productID uuid.UUID, limit int) ([]*domain.PriceHistory, error) {
+	var history []*domain.PriceHistory
+	if err := r.db.Where("product_id = ?", productID).
+		Order("changed_at DESC").
+		Limit(limit).
+		Find(&history).Error; err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+type PostgresPriceScheduleRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresPriceScheduleRepository(db *gorm.DB) *PostgresPriceScheduleRepository {
+	return &PostgresPriceScheduleRepository{db: db}
+}
+
+func (r *PostgresPriceScheduleRepository) Create(schedule *domain.PriceSchedule) error {
+	return r.db.Create(schedule).Error
+}
+
+func (r *PostgresPriceScheduleRepository) FindByID(id uuid.UUID) (*domain.PriceSchedule, error) {
+	var schedule domain.PriceSchedule
+	if err := r.db.Where("id = ?", id).First(&schedule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrScheduleNotFound
+		}
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+func (r *PostgresPriceScheduleRepository) FindByProductID(productID uuid.UUID) ([]*domain.PriceSchedule, error) {
+	var schedules []*domain.PriceSchedule
+	if err := r.db.Where("product_id = ?", productID).
+		Order("starts_at DESC").
+		Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+// FindOpenByProductID возвращает ожидающие и действующие расписания товара
+func (r *PostgresPriceScheduleRepository) FindOpenByProductID(productID uuid.UUID) ([]*domain.PriceSchedule, error) {
+	var schedules []*domain.PriceSchedule
+	if err := r.db.Where("product_id = ? AND status IN ?", productID,
+		[]domain.PriceScheduleStatus{domain.SchedulePending, domain.ScheduleActive}).
+		Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+// FindDue возвращает расписания, которые пора запустить или завершить
+func (r *PostgresPriceScheduleRepository) FindDue(now time.Time, limit int) ([]*domain.PriceSchedule, error) {
+	var schedules []*domain.PriceSchedule
+	if err := r.db.Where("(status = ? AND starts_at <= ?) OR (status = ? AND ends_at <= ?)",
+		domain.SchedulePending, now, domain.ScheduleActive, now).
+		Order("starts_at").
+		Limit(limit).
+		Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+// Transition сохраняет расписание, только если его статус в базе все еще равен from.
+// Так одно расписание не обработают две реплики одновременно.
+func (r *PostgresPriceScheduleRepository) Transition(schedule *domain.PriceSchedule, from domain.PriceScheduleStatus) (bool, error) {
+	result := r.db.Model(&domain.PriceSchedule{}).
+		Where("id = ? AND status = ?", schedule.ID, from).
+		Updates(map[string]interface{}{
+			"status":         schedule.Status,
+			"previous_price": schedule.PreviousPrice,
+			"ends_at":        schedule.EndsAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
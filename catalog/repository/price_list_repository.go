@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PriceListRepository interface {
+	Create(priceList *domain.PriceList) error
+	FindByID(id uuid.UUID) (*domain.PriceList, error)
+	FindAll() ([]*domain.PriceList, error)
+	FindByCurrency(currency string) ([]*domain.PriceList, error)
+	UpsertEntry(entry *domain.PriceListEntry) error
+	DeleteEntry(priceListID, productID uuid.UUID) error
+	FindEntries(priceListID uuid.UUID, productIDs []uuid.UUID) ([]*domain.PriceListEntry, error)
+}
+
+type PostgresPriceListRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresPriceListRepository(db *gorm.DB) *PostgresPriceListRepository {
+	return &PostgresPriceListRepository{db: db}
+}
+
+// Create сохраняет прайс-лист; второй прайс-лист той же валюты и рынка дает domain.ErrPriceListExists
+func (r *PostgresPriceListRepository) Create(priceList *domain.PriceList) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if priceList.IsDefault {
+			// У валюты может быть только один прайс-лист по умолчанию
+			if err := tx.Model(&domain.PriceList{}).
+				Where("currency = ?", priceList.Currency).
+				Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(priceList).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return domain.ErrPriceListExists
+	}
+
+	return err
+}
+
+func (r *PostgresPriceListRepository) FindByID(id uuid.UUID) (*domain.PriceList, error) {
+	var priceList domain.PriceList
+	if err := r.db.Where("id = ?", id).First(&priceList).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPriceListNotFound
+		}
+		return nil, err
+	}
+
+	return &priceList, nil
+}
+
+func (r *PostgresPriceListRepository) FindAll() ([]*domain.PriceList, error) {
+	var priceLists []*domain.PriceList
+	if err := r.db.Order("currency, market").Find(&priceLists).Error; err != nil {
+		return nil, err
+	}
+
+	return priceLists, nil
+}
+
+func (r *PostgresPriceListRepository) FindByCurrency(currency string) ([]*domain.PriceList, error) {
+	var priceLists []*domain.PriceList
+	if err := r.db.Where("currency = ?", currency).Find(&priceLists).Error; err != nil {
+		return nil, err
+	}
+
+	return priceLists, nil
+}
+
+func (r *PostgresPriceListRepository) UpsertEntry(entry *domain.PriceListEntry) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "price_list_id"}, {Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"price"}),
+	}).Create(entry).Error
+}
+
+func (r *PostgresPriceListRepository) DeleteEntry(priceListID, productID uuid.UUID) error {
+	return r.db.Where("price_list_id = ? AND product_id = ?", priceListID, productID).
+		Delete(&domain.PriceListEntry{}).Error
+}
+
+func (r *PostgresPriceListRepository) FindEntries(priceListID uuid.UUID, productIDs []uuid.UUID) ([]*domain.PriceListEntry, error) {
+	var entries []*domain.PriceListEntry
+	if err := r.db.Where("price_list_id = ? AND product_id IN ?", priceListID, productIDs).
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sort"
+	"time"
+)
+
+type RecommendationRepository interface {
+	RecordOrder(orderID uuid.UUID, pairs []domain.CoPurchase) error
+	FindCoPurchased(productID uuid.UUID, limit int) ([]uuid.UUID, error)
+	FindSameAttribute(productID uuid.UUID, code string, exclude []uuid.UUID, limit int) ([]uuid.UUID, error)
+}
+
+type PostgresRecommendationRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresRecommendationRepository(db *gorm.DB) *PostgresRecommendationRepository {
+	return &PostgresRecommendationRepository{db: db}
+}
+
+// RecordOrder увеличивает счетчики совместных покупок на пары из заказа.
+// Повторный заказ возвращает domain.ErrEventAlreadyProcessed без изменения счетчиков.
+func (r *PostgresRecommendationRepository) RecordOrder(orderID uuid.UUID, pairs []domain.CoPurchase) error {
+	// Одинаковый порядок строк в конкурирующих транзакциях исключает взаимные блокировки
+	pairs = append([]domain.CoPurchase(nil), pairs...)
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].ProductID != pairs[j].ProductID {
+			return pairs[i].ProductID.String() < pairs[j].ProductID.String()
+		}
+		return pairs[i].RelatedID.String() < pairs[j].RelatedID.String()
+	})
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&domain.CoPurchaseOrder{
+			OrderID:    orderID,
+			RecordedAt: time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrEventAlreadyProcessed
+		}
+		if len(pairs) == 0 {
+			return nil
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "product_id"}, {Name: "related_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("co_purchases.count + excluded.count")}),
+		}).CreateInBatches(&pairs, 1000).Error
+	})
+}
+
+// FindCoPurchased возвращает публичные товары, которые чаще всего покупали вместе с productID
+func (r *PostgresRecommendationRepository) FindCoPurchased(productID uuid.UUID, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := r.db.Model(&domain.CoPurchase{}).
+		Joins("JOIN products ON products.id = co_purchases.related_id AND products.deleted_at IS NULL").
+		Where("co_purchases.product_id = ? AND products.status = ?", productID, domain.ProductActive).
+		Order("co_purchases.count DESC, co_purchases.related_id").
+		Limit(limit).
+		Pluck("co_purchases.related_id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// FindSameAttribute возвращает публичные товары с тем же значением характеристики code, что у productID.
+// Популярные по числу отзывов идут первыми.
+func (r *PostgresRecommendationRepository) FindSameAttribute(productID uuid.UUID, code string, exclude []uuid.UUID, limit int) ([]uuid.UUID, error) {
+	query := r.db.Model(&domain.Product{}).
+		Joins("JOIN product_attributes pa ON pa.product_id = products.id AND pa.code = ?", code).
+		Joins("JOIN product_attributes own ON own.product_id = ? AND own.code = pa.code AND own.value = pa.value", productID).
+		Where("products.id <> ? AND products.status = ?", productID, domain.ProductActive)
+	if len(exclude) > 0 {
+		query = query.Where("products.id NOT IN ?", exclude)
+	}
+
+	var ids []uuid.UUID
+	if err := query.
+		Order("products.rating_count DESC, products.id").
+		Limit(limit).
+		Pluck("products.id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type ReplenishmentRepository interface {
+	RecordSales(sales []domain.ProductSale) error
+	RemoveSales(orderID uuid.UUID, productIDs []uuid.UUID) error
+	FindBelowThreshold() ([]*domain.Product, error)
+	CountSales(productIDs []uuid.UUID, since time.Time) (map[uuid.UUID]int, error)
+}
+
+type PostgresReplenishmentRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresReplenishmentRepository(db *gorm.DB) *PostgresReplenishmentRepository {
+	return &PostgresReplenishmentRepository{db: db}
+}
+
+// RecordSales сохраняет продажи заказа; повторное событие того же заказа ничего не меняет
+func (r *PostgresReplenishmentRepository) RecordSales(sales []domain.ProductSale) error {
+	if len(sales) == 0 {
+		return nil
+	}
+
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&sales).Error
+}
+
+// RemoveSales удаляет продажи заказа; без productIDs удаляется весь заказ
+func (r *PostgresReplenishmentRepository) RemoveSales(orderID uuid.UUID, productIDs []uuid.UUID) error {
+	query := r.db.Where("order_id = ?", orderID)
+	if len(productIDs) > 0 {
+		query = query.Where("product_id IN ?", productIDs)
+	}
+
+	return query.Delete(&domain.ProductSale{}).Error
+}
+
+// FindBelowThreshold возвращает неархивные товары, остаток которых опустился до порога дозаказа.
+// Наборы не дозаказывают: их остаток складывается из компонентов.
+func (r *PostgresReplenishmentRepository) FindBelowThreshold() ([]*domain.Product, error) {
+	var products []*domain.Product
+	if err := r.db.
+		Where("reorder_threshold > 0 AND stock <= reorder_threshold").
+		Where("type = ? AND status <> ?", domain.ProductSimple, domain.ProductArchived).
+		Order("sku").
+		Find(&products).Error; err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// CountSales возвращает проданное с since количество каждого из товаров; непроданных в ответе нет
+func (r *PostgresReplenishmentRepository) CountSales(productIDs []uuid.UUID, since time.Time) (map[uuid.UUID]int, error) {
+	var rows []struct {
+		ProductID uuid.UUID
+		Sold      int
+	}
+	if err := r.db.Model(&domain.ProductSale{}).
+		Select("product_id, SUM(quantity) AS sold").
+		Where("product_id IN ? AND sold_at >= ?", productIDs, since).
+		Group("product_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	sold := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		sold[row.ProductID] = row.Sold
+	}
+
+	return sold, nil
+}
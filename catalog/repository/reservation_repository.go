@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sort"
+	"time"
+)
+
+type ReservationRepository interface {
+	Reserve(reservation *domain.Reservation) ([]domain.StockChange, error)
+	FindByReferenceID(referenceID string) (*domain.Reservation, error)
+	Commit(referenceID string) (*domain.Reservation, error)
+	Release(referenceID string) (*domain.Reservation, []domain.StockChange, error)
+	ReleaseExpired(now time.Time, limit int) (int, []domain.StockChange, error)
+	CountActiveByCreator(email string, now time.Time) (int64, error)
+}
+
+type PostgresReservationRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresReservationRepository(db *gorm.DB) *PostgresReservationRepository {
+	return &PostgresReservationRepository{db: db}
+}
+
+// Reserve списывает остатки под резерв в одной транзакции. Остаток забирается со складов
+// в порядке приоритета, и у каждой позиции резерва запоминается ее склад.
+// Строки товаров блокируются в порядке ID, чтобы параллельные резервы не взаимоблокировались.
+// Повтор ReferenceID отсекает уникальный индекс, поэтому резерв сохраняется до списания остатков.
+func (r *PostgresReservationRepository) Reserve(reservation *domain.Reservation) ([]domain.StockChange, error) {
+	var changes []domain.StockChange
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Items").Create(reservation).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return domain.ErrReservationDuplicated
+			}
+			return err
+		}
+
+		items := append([]domain.ReservationItem(nil), reservation.Items...)
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].ProductID.String() < items[j].ProductID.String()
+		})
+
+		var allocated []domain.ReservationItem
+		for _, item := range items {
+			var product domain.Product
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", item.ProductID).
+				First(&product).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("%w: %s", domain.ErrProductNotFound, item.ProductID)
+				}
+				return err
+			}
+			if product.Stock < item.Quantity {
+				return fmt.Errorf("%w: product %s has %d, requested %d",
+					domain.ErrInsufficientStock, product.ID, product.Stock, item.Quantity)
+			}
+			if err := tx.Model(&domain.Product{}).
+				Where("id = ?", item.ProductID).
+				Updates(map[string]interface{}{
+					"stock":   gorm.Expr("stock - ?", item.Quantity),
+					"version": gorm.Expr("version + 1"),
+				}).Error; err != nil {
+				return err
+			}
+
+			// Позиция резерва делится по складам, с которых списан остаток
+			adjustments, err := drainStock(tx, item.ProductID, item.Quantity, domain.AdjustmentReservation, reservation.ReferenceID)
+			if err != nil {
+				return err
+			}
+			drained := 0
+			for _, adjustment := range adjustments {
+				warehouseID := adjustment.WarehouseID
+				allocated = append(allocated, domain.ReservationItem{
+					ID:            uuid.New(),
+					ReservationID: reservation.ID,
+					ProductID:     item.ProductID,
+					Quantity:      -adjustment.Delta,
+					WarehouseID:   &warehouseID,
+				})
+				drained -= adjustment.Delta
+			}
+			if drained < item.Quantity {
+				return fmt.Errorf("%w: product %s has %d across warehouses, requested %d",
+					domain.ErrInsufficientStock, product.ID, drained, item.Quantity)
+			}
+
+			changes = append(changes, domain.StockChange{
+				ProductID: product.ID,
+				Before:    product.Stock,
+				After:     product.Stock - item.Quantity,
+			})
+		}
+
+		reservation.Items = allocated
+		if len(allocated) == 0 {
+			return nil
+		}
+		return tx.Create(&allocated).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+func (r *PostgresReservationRepository) FindByReferenceID(referenceID string) (*domain.Reservation, error) {
+	var reservation domain.Reservation
+	if err := r.db.Preload("Items").
+		Where("reference_id = ?", referenceID).
+		First(&reservation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrReservationNotFound
+		}
+		return nil, err
+	}
+
+	return &reservation, nil
+}
+
+func (r *PostgresReservationRepository) Commit(referenceID string) (*domain.Reservation, error) {
+	var reservation *domain.Reservation
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		reservation, err = lockActiveReservation(tx, referenceID)
+		if err != nil {
+			return err
+		}
+		if reservation.IsExpired(time.Now()) {
+			return domain.ErrReservationNotActive
+		}
+
+		return updateReservationStatus(tx, reservation, domain.ReservationCommitted)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+func (r *PostgresReservationRepository) Release(referenceID string) (*domain.Reservation, []domain.StockChange, error) {
+	var reservation *domain.Reservation
+	var changes []domain.StockChange
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		reservation, err = lockActiveReservation(tx, referenceID)
+		if err != nil {
+			return err
+		}
+
+		changes, err = releaseReservation(tx, reservation)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return reservation, changes, nil
+}
+
+// ReleaseExpired возвращает на склад остатки просроченных резервов.
+// SKIP LOCKED позволяет нескольким репликам запускать очистку одновременно.
+func (r *PostgresReservationRepository) ReleaseExpired(now time.Time, limit int) (int, []domain.StockChange, error) {
+	var released int
+	var changes []domain.StockChange
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var reservations []*domain.Reservation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND expires_at <= ?", domain.ReservationActive, now).
+			Order("expires_at").
+			Limit(limit).
+			Find(&reservations).Error; err != nil {
+			return err
+		}
+
+		for _, reservation := range reservations {
+			if err := tx.Where("reservation_id = ?", reservation.ID).Find(&reservation.Items).Error; err != nil {
+				return err
+			}
+			reservationChanges, err := releaseReservation(tx, reservation)
+			if err != nil {
+				return err
+			}
+			changes = append(changes, reservationChanges...)
+			released++
+		}
+		return nil
+	})
+
+	return released, changes, err
+}
+
+func lockActiveReservation(tx *gorm.DB, referenceID string) (*domain.Reservation, error) {
+	var reservation domain.Reservation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("reference_id = ?", referenceID).
+		First(&reservation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrReservationNotFound
+		}
+		return nil, err
+	}
+	if reservation.Status != domain.ReservationActive {
+		return nil, domain.ErrReservationNotActive
+	}
+	if err := tx.Where("reservation_id = ?", reservation.ID).Find(&reservation.Items).Error; err != nil {
+		return nil, err
+	}
+
+	return &reservation, nil
+}
+
+func releaseReservation(tx *gorm.DB, reservation *domain.Reservation) ([]domain.StockChange, error) {
+	changes := make([]domain.StockChange, 0, len(reservation.Items))
+	for _, item := range reservation.Items {
+		var product domain.Product
+		if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", item.ProductID).
+			First(&product).Error; err != nil {
+			return nil, err
+		}
+		if err := tx.Unscoped().Model(&domain.Product{}).
+			Where("id = ?", item.ProductID).
+			Updates(map[string]interface{}{
+				"stock":   gorm.Expr("stock + ?", item.Quantity),
+				"version": gorm.Expr("version + 1"),
+			}).Error; err != nil {
+			return nil, err
+		}
+		if err := restock(tx, item.WarehouseID, item.ProductID, item.Quantity, domain.AdjustmentReservationRelease, reservation.ReferenceID); err != nil {
+			return nil, err
+		}
+		changes = append(changes, domain.StockChange{
+			ProductID: item.ProductID,
+			Before:    product.Stock,
+			After:     product.Stock + item.Quantity,
+		})
+	}
+
+	return changes, updateReservationStatus(tx, reservation, domain.ReservationReleased)
+}
+
+func updateReservationStatus(tx *gorm.DB, reservation *domain.Reservation, status domain.ReservationStatus) error {
+	reservation.Status = status
+	reservation.UpdatedAt = time.Now()
+	return tx.Model(&domain.Reservation{}).
+		Where("id = ?", reservation.ID).
+		Updates(map[string]interface{}{"status": status, "updated_at": reservation.UpdatedAt}).Error
+}
+
+// CountActiveByCreator считает неистекшие активные резервы пользователя
+func (r *PostgresReservationRepository) CountActiveByCreator(email string, now time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Reservation{}).
+		Where("created_by = ? AND status = ? AND expires_at > ?", email, domain.ReservationActive, now).
+		Count(&count).Error
+
+	return count, err
+}
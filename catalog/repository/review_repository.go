@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type ReviewRepository interface {
+	Create(review *domain.Review) error
+	Update(review *domain.Review) error
+	Delete(review *domain.Review) error
+	FindByID(id uuid.UUID) (*domain.Review, error)
+	FindByProductID(productID uuid.UUID, offset, limit int) ([]*domain.Review, int64, error)
+}
+
+type PurchaseRepository interface {
+	RecordOrder(order *domain.OrderEvent) error
+	MarkDelivered(order *domain.OrderEvent, deliveredAt time.Time) error
+	Remove(orderID uuid.UUID, productIDs []uuid.UUID) error
+	HasDelivered(userEmail string, productID uuid.UUID) (bool, error)
+}
+
+type PostgresReviewRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresReviewRepository(db *gorm.DB) *PostgresReviewRepository {
+	return &PostgresReviewRepository{db: db}
+}
+
+// Create сохраняет отзыв и пересчитывает рейтинг. Второй отзыв того же пользователя
+// отсекает уникальный индекс, в том числе при одновременных запросах.
+func (r *PostgresReviewRepository) Create(review *domain.Review) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(review).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return domain.ErrReviewExists
+			}
+			return err
+		}
+		return refreshRating(tx, review.ProductID)
+	})
+}
+
+func (r *PostgresReviewRepository) Update(review *domain.Review) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Review{}).
+			Where("id = ?", review.ID).
+			Updates(map[string]interface{}{
+				"rating":     review.Rating,
+				"title":      review.Title,
+				"comment":    review.Comment,
+				"updated_at": review.UpdatedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrReviewNotFound
+		}
+		return refreshRating(tx, review.ProductID)
+	})
+}
+
+func (r *PostgresReviewRepository) Delete(review *domain.Review) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&domain.Review{}, "id = ?", review.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrReviewNotFound
+		}
+		return refreshRating(tx, review.ProductID)
+	})
+}
+
+// refreshRating пересчитывает средний рейтинг и число отзывов товара.
+// Версия товара не меняется: отзывы не конфликтуют с редактированием карточки.
+func refreshRating(tx *gorm.DB, productID uuid.UUID) error {
+	return tx.Exec(`UPDATE products SET
+		rating_count = (SELECT COUNT(*) FROM reviews WHERE product_id = @id),
+		rating_average = (SELECT COALESCE(ROUND(AVG(rating), 2), 0) FROM reviews WHERE product_id = @id),
+		updated_at = now()
+		WHERE id = @id`, map[string]interface{}{"id": productID}).Error
+}
+
+func (r *PostgresReviewRepository) FindByID(id uuid.UUID) (*domain.Review, error) {
+	var review domain.Review
+	if err := r.db.Where("id = ?", id).First(&review).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrReviewNotFound
+		}
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+// FindByProductID возвращает страницу отзывов товара, новые первыми, и общее число отзывов
+func (r *PostgresReviewRepository) FindByProductID(productID uuid.UUID, offset, limit int) ([]*domain.Review, int64, error) {
+	var total int64
+	if err := r.db.Model(&domain.Review{}).Where("product_id = ?", productID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var reviews []*domain.Review
+	if err := r.db.Where("product_id = ?", productID).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&reviews).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return reviews, total, nil
+}
+
+type PostgresPurchaseRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresPurchaseRepository(db *gorm.DB) *PostgresPurchaseRepository {
+	return &PostgresPurchaseRepository{db: db}
+}
+
+// RecordOrder сохраняет позиции заказа; повторная доставка события ничего не меняет
+func (r *PostgresPurchaseRepository) RecordOrder(order *domain.OrderEvent) error {
+	purchases := domain.NewPurchases(order, nil)
+	if len(purchases) == 0 {
+		return nil
+	}
+
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&purchases).Error
+}
+
+// MarkDelivered отмечает позиции заказа доставленными. orders.delivered может прийти раньше
+// orders.created, поэтому позиции из события вставляются, если их еще нет.
+func (r *PostgresPurchaseRepository) MarkDelivered(order *domain.OrderEvent, deliveredAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if purchases := domain.NewPurchases(order, &deliveredAt); len(purchases) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "order_id"}, {Name: "product_id"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"delivered_at": deliveredAt}),
+				Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "purchases.delivered_at IS NULL"}}},
+			}).Create(&purchases).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&domain.Purchase{}).
+			Where("order_id = ? AND delivered_at IS NULL", order.ID).
+			Update("delivered_at", deliveredAt).Error
+	})
+}
+
+// Remove удаляет позиции заказа; без productIDs удаляется весь заказ
+func (r *PostgresPurchaseRepository) Remove(orderID uuid.UUID, productIDs []uuid.UUID) error {
+	query := r.db.Where("order_id = ?", orderID)
+	if len(productIDs) > 0 {
+		query = query.Where("product_id IN ?", productIDs)
+	}
+
+	return query.Delete(&domain.Purchase{}).Error
+}
+
+func (r *PostgresPurchaseRepository) HasDelivered(userEmail string, productID uuid.UUID) (bool, error) {
+	var count int64
+	if err := r.db.Model(&domain.Purchase{}).
+		Where("user_email = ? AND product_id = ? AND delivered_at IS NOT NULL", userEmail, productID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
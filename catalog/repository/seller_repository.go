@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SellerRepository interface {
+	FindOrCreate(email string) (*domain.Seller, error)
+	FindByEmail(email string) (*domain.Seller, error)
+	FindByID(id uuid.UUID) (*domain.Seller, error)
+}
+
+type PostgresSellerRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresSellerRepository(db *gorm.DB) *PostgresSellerRepository {
+	return &PostgresSellerRepository{db: db}
+}
+
+// FindOrCreate возвращает продавца с email, заводя его при первом обращении
+func (r *PostgresSellerRepository) FindOrCreate(email string) (*domain.Seller, error) {
+	seller := domain.NewSeller(email)
+	if err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "email"}},
+		DoNothing: true,
+	}).Create(seller).Error; err != nil {
+		return nil, err
+	}
+
+	// При конфликте строка не вставлена, и ID нужно прочитать из существующей
+	return r.FindByEmail(seller.Email)
+}
+
+func (r *PostgresSellerRepository) FindByEmail(email string) (*domain.Seller, error) {
+	var seller domain.Seller
+	if err := r.db.Where("email = ?", domain.NormalizeEmail(email)).First(&seller).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrSellerNotFound
+		}
+		return nil, err
+	}
+
+	return &seller, nil
+}
+
+func (r *PostgresSellerRepository) FindByID(id uuid.UUID) (*domain.Seller, error) {
+	var seller domain.Seller
+	if err := r.db.Where("id = ?", id).First(&seller).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrSellerNotFound
+		}
+		return nil, err
+	}
+
+	return &seller, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StockSubscriptionRepository interface {
+	Create(subscription *domain.StockSubscription) error
+	Delete(productID uuid.UUID, userEmail string) error
+	FindSubscribers(productID uuid.UUID) ([]string, error)
+	DeleteSubscribers(productID uuid.UUID, emails []string) error
+	FindSubscribedBundles(componentID uuid.UUID) ([]uuid.UUID, error)
+}
+
+type PostgresStockSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresStockSubscriptionRepository(db *gorm.DB) *PostgresStockSubscriptionRepository {
+	return &PostgresStockSubscriptionRepository{db: db}
+}
+
+// Create сохраняет подписку; повторная подписка того же пользователя ничего не меняет
+func (r *PostgresStockSubscriptionRepository) Create(subscription *domain.StockSubscription) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(subscription).Error
+}
+
+func (r *PostgresStockSubscriptionRepository) Delete(productID uuid.UUID, userEmail string) error {
+	result := r.db.Where("product_id = ? AND user_email = ?", productID, userEmail).Delete(&domain.StockSubscription{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrSubscriptionNotFound
+	}
+
+	return nil
+}
+
+// FindSubscribers возвращает адреса подписчиков на товар
+func (r *PostgresStockSubscriptionRepository) FindSubscribers(productID uuid.UUID) ([]string, error) {
+	var emails []string
+	if err := r.db.Model(&domain.StockSubscription{}).
+		Where("product_id = ?", productID).
+		Order("created_at").
+		Pluck("user_email", &emails).Error; err != nil {
+		return nil, err
+	}
+
+	return emails, nil
+}
+
+// DeleteSubscribers удаляет подписки уведомленных пользователей. Подписки, оформленные
+// после чтения списка, остаются до следующего поступления.
+func (r *PostgresStockSubscriptionRepository) DeleteSubscribers(productID uuid.UUID, emails []string) error {
+	return r.db.Where("product_id = ? AND user_email IN ?", productID, emails).Delete(&domain.StockSubscription{}).Error
+}
+
+// FindSubscribedBundles возвращает наборы с подписчиками, в которые входит товар
+func (r *PostgresStockSubscriptionRepository) FindSubscribedBundles(componentID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := r.db.Model(&domain.BundleComponent{}).
+		Distinct("bundle_id").
+		Where("product_id = ?", componentID).
+		Where("EXISTS (SELECT 1 FROM stock_subscriptions s WHERE s.product_id = bundle_components.bundle_id)").
+		Pluck("bundle_id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TaxRateRepository interface {
+	Save(rate *domain.TaxRate) error
+	FindAll() ([]*domain.TaxRate, error)
+	FindByCountry(country string) ([]*domain.TaxRate, error)
+	Delete(id uuid.UUID) error
+}
+
+type PostgresTaxRateRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresTaxRateRepository(db *gorm.DB) *PostgresTaxRateRepository {
+	return &PostgresTaxRateRepository{db: db}
+}
+
+// Save создает ставку или меняет существующую для той же страны, региона и класса
+func (r *PostgresTaxRateRepository) Save(rate *domain.TaxRate) error {
+	// При обновлении RETURNING вернет прежний ID ставки
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "country"}, {Name: "region"}, {Name: "tax_class"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+	}).Create(rate).Error
+}
+
+func (r *PostgresTaxRateRepository) FindAll() ([]*domain.TaxRate, error) {
+	var rates []*domain.TaxRate
+	if err := r.db.Order("country, region, tax_class").Find(&rates).Error; err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+func (r *PostgresTaxRateRepository) FindByCountry(country string) ([]*domain.TaxRate, error) {
+	var rates []*domain.TaxRate
+	if err := r.db.Where("country = ?", country).Find(&rates).Error; err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+func (r *PostgresTaxRateRepository) Delete(id uuid.UUID) error {
+	result := r.db.Where("id = ?", id).Delete(&domain.TaxRate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrTaxRateNotFound
+	}
+
+	return nil
+}
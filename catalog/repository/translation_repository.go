@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TranslationRepository interface {
+	Save(translation *domain.ProductTranslation) error
+	Delete(productID uuid.UUID, locale string) error
+	FindByProductID(productID uuid.UUID) ([]*domain.ProductTranslation, error)
+	FindByProductIDs(productIDs []uuid.UUID, locales []string) ([]*domain.ProductTranslation, error)
+	FindProductsMissing(locale string) ([]*domain.Product, error)
+}
+
+type PostgresTranslationRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresTranslationRepository(db *gorm.DB) *PostgresTranslationRepository {
+	return &PostgresTranslationRepository{db: db}
+}
+
+// Save создает или заменяет перевод и увеличивает версию товара: перевод входит в его представление
+func (r *PostgresTranslationRepository) Save(translation *domain.ProductTranslation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpProductVersion(tx, translation.ProductID); err != nil {
+			return err
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "product_id"}, {Name: "locale"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "description", "updated_at"}),
+		}).Create(translation).Error
+	})
+}
+
+func (r *PostgresTranslationRepository) Delete(productID uuid.UUID, locale string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("product_id = ? AND locale = ?", productID, locale).Delete(&domain.ProductTranslation{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrTranslationNotFound
+		}
+
+		return bumpProductVersion(tx, productID)
+	})
+}
+
+func (r *PostgresTranslationRepository) FindByProductID(productID uuid.UUID) ([]*domain.ProductTranslation, error) {
+	var translations []*domain.ProductTranslation
+	if err := r.db.Where("product_id = ?", productID).Order("locale").Find(&translations).Error; err != nil {
+		return nil, err
+	}
+
+	return translations, nil
+}
+
+// FindByProductIDs загружает переводы нескольких товаров на указанные языки одним запросом
+func (r *PostgresTranslationRepository) FindByProductIDs(productIDs []uuid.UUID, locales []string) ([]*domain.ProductTranslation, error) {
+	var translations []*domain.ProductTranslation
+	if err := r.db.Where("product_id IN ? AND locale IN ?", productIDs, locales).Find(&translations).Error; err != nil {
+		return nil, err
+	}
+
+	return translations, nil
+}
+
+// FindProductsMissing возвращает неудаленные товары без перевода на locale
+func (r *PostgresTranslationRepository) FindProductsMissing(locale string) ([]*domain.Product, error) {
+	var products []*domain.Product
+	if err := r.db.
+		Where("NOT EXISTS (SELECT 1 FROM product_translations pt WHERE pt.product_id = products.id AND pt.locale = ?)", locale).
+		Order("name").
+		Find(&products).Error; err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type WarehouseRepository interface {
+	Create(warehouse *domain.Warehouse) error
+	FindAll() ([]*domain.Warehouse, error)
+	FindByID(id uuid.UUID) (*domain.Warehouse, error)
+	Update(warehouse *domain.Warehouse) error
+	FindProductStock(productID uuid.UUID) ([]domain.WarehouseStockLevel, error)
+	Adjust(adjustment *domain.StockAdjustment) (domain.StockChange, error)
+	Transfer(transfer *domain.StockTransfer) ([]*domain.StockAdjustment, error)
+	FindAdjustments(filter domain.StockAdjustmentFilter, limit int) ([]*domain.StockAdjustment, error)
+}
+
+type PostgresWarehouseRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresWarehouseRepository(db *gorm.DB) *PostgresWarehouseRepository {
+	return &PostgresWarehouseRepository{db: db}
+}
+
+func (r *PostgresWarehouseRepository) Create(warehouse *domain.Warehouse) error {
+	var count int64
+	if err := r.db.Model(&domain.Warehouse{}).Where("code = ?", warehouse.Code).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return domain.ErrWarehouseExists
+	}
+
+	return r.db.Create(warehouse).Error
+}
+
+func (r *PostgresWarehouseRepository) FindAll() ([]*domain.Warehouse, error) {
+	var warehouses []*domain.Warehouse
+	if err := r.db.Order("priority, code").Find(&warehouses).Error; err != nil {
+		return nil, err
+	}
+
+	return warehouses, nil
+}
+
+func (r *PostgresWarehouseRepository) FindByID(id uuid.UUID) (*domain.Warehouse, error) {
+	return findWarehouse(r.db, id)
+}
+
+// Update сохраняет название, приоритет и активность склада. Склад с остатками отключить нельзя:
+// сумма остатков по складам должна совпадать с остатком товара.
+func (r *PostgresWarehouseRepository) Update(warehouse *domain.Warehouse) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := findWarehouse(tx.Clauses(clause.Locking{Strength: "UPDATE"}), warehouse.ID); err != nil {
+			return err
+		}
+
+		if !warehouse.Active {
+			var count int64
+			if err := tx.Model(&domain.WarehouseStock{}).
+				Where("warehouse_id = ? AND quantity <> 0", warehouse.ID).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return domain.ErrWarehouseNotEmpty
+			}
+		}
+
+		return tx.Model(&domain.Warehouse{}).
+			Where("id = ?", warehouse.ID).
+			Updates(map[string]interface{}{
+				"name":     warehouse.Name,
+				"priority": warehouse.Priority,
+				"active":   warehouse.Active,
+			}).Error
+	})
+}
+
+// FindProductStock возвращает остатки товара по активным складам и по складам, где товар еще лежит
+func (r *PostgresWarehouseRepository) FindProductStock(productID uuid.UUID) ([]domain.WarehouseStockLevel, error) {
+	var count int64
+	if err := r.db.Model(&domain.Product{}).Where("id = ?", productID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, domain.ErrProductNotFound
+	}
+
+	var levels []domain.WarehouseStockLevel
+	err := r.db.Raw(`SELECT w.id AS warehouse_id, w.code, w.name, w.priority,
+			COALESCE(ws.quantity, 0) AS available,
+			COALESCE((SELECT SUM(ri.quantity) FROM reservation_items ri
+				JOIN reservations res ON res.id = ri.reservation_id
+				WHERE res.status = @active AND ri.product_id = @product AND ri.warehouse_id = w.id), 0) AS reserved
+		FROM warehouses w
+		LEFT JOIN warehouse_stocks ws ON ws.warehouse_id = w.id AND ws.product_id = @product
+		WHERE w.active OR COALESCE(ws.quantity, 0) <> 0
+		ORDER BY w.priority, w.code`, map[string]interface{}{
+		"product": productID,
+		"active":  domain.ReservationActive,
+	}).Scan(&levels).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return levels, nil
+}
+
+// Adjust изменяет остаток товара на складе и общий остаток товара, записывая движение в журнал.
+// Остаток на складе не может стать отрицательным.
+func (r *PostgresWarehouseRepository) Adjust(adjustment *domain.StockAdjustment) (domain.StockChange, error) {
+	var change domain.StockChange
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		product, err := lockProduct(tx, adjustment.ProductID)
+		if err != nil {
+			return err
+		}
+		if product.IsBundle() {
+			return fmt.Errorf("%w: bundle stock is derived from its components", domain.ErrInvalidAdjustment)
+		}
+		warehouse, err := findWarehouse(tx, adjustment.WarehouseID)
+		if err != nil {
+			return err
+		}
+		if !warehouse.Active && adjustment.Delta > 0 {
+			return fmt.Errorf("%w: warehouse %s is inactive", domain.ErrInvalidAdjustment, warehouse.Code)
+		}
+
+		if err := changeWarehouseStock(tx, adjustment); err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.Product{}).
+			Where("id = ?", product.ID).
+			Updates(map[string]interface{}{
+				"stock":   gorm.Expr("stock + ?", adjustment.Delta),
+				"version": gorm.Expr("version + 1"),
+			}).Error; err != nil {
+			return err
+		}
+
+		change = domain.StockChange{
+			ProductID: product.ID,
+			Before:    product.Stock,
+			After:     product.Stock + adjustment.Delta,
+		}
+		return nil
+	})
+
+	return change, err
+}
+
+// Transfer перемещает товар между складами. Общий остаток и версия товара не меняются.
+func (r *PostgresWarehouseRepository) Transfer(transfer *domain.StockTransfer) ([]*domain.StockAdjustment, error) {
+	var adjustments []*domain.StockAdjustment
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Блокировка товара упорядочивает перемещение с резервами и корректировками
+		if _, err := lockProduct(tx, transfer.ProductID); err != nil {
+			return err
+		}
+		from, err := findWarehouse(tx, transfer.FromWarehouseID)
+		if err != nil {
+			return err
+		}
+		to, err := findWarehouse(tx, transfer.ToWarehouseID)
+		if err != nil {
+			return err
+		}
+		if !to.Active {
+			return fmt.Errorf("%w: warehouse %s is inactive", domain.ErrInvalidAdjustment, to.Code)
+		}
+
+		reference := transfer.ID.String()
+		out := domain.NewStockAdjustment(transfer.ProductID, from.ID, -transfer.Quantity, domain.AdjustmentTransferOut, reference)
+		in := domain.NewStockAdjustment(transfer.ProductID, to.ID, transfer.Quantity, domain.AdjustmentTransferIn, reference)
+		for _, adjustment := range []*domain.StockAdjustment{out, in} {
+			adjustment.Note = transfer.Note
+			adjustment.Actor = transfer.Actor
+			if err := changeWarehouseStock(tx, adjustment); err != nil {
+				return err
+			}
+		}
+
+		adjustments = []*domain.StockAdjustment{out, in}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return adjustments, nil
+}
+
+// FindAdjustments возвращает записи журнала, новые первыми
+func (r *PostgresWarehouseRepository) FindAdjustments(filter domain.StockAdjustmentFilter, limit int) ([]*domain.StockAdjustment, error) {
+	query := r.db.Model(&domain.StockAdjustment{})
+	if filter.ProductID != uuid.Nil {
+		query = query.Where("product_id = ?", filter.ProductID)
+	}
+	if filter.WarehouseID != uuid.Nil {
+		query = query.Where("warehouse_id = ?", filter.WarehouseID)
+	}
+	if filter.Reason != "" {
+		query = query.Where("reason = ?", filter.Reason)
+	}
+
+	var adjustments []*domain.StockAdjustment
+	if err := query.Order("created_at DESC").Limit(limit).Find(&adjustments).Error; err != nil {
+		return nil, err
+	}
+
+	return adjustments, nil
+}
+
+func findWarehouse(db *gorm.DB, id uuid.UUID) (*domain.Warehouse, error) {
+	var warehouse domain.Warehouse
+	if err := db.Where("id = ?", id).First(&warehouse).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrWarehouseNotFound
+		}
+		return nil, err
+	}
+
+	return &warehouse, nil
+}
+
+func lockProduct(tx *gorm.DB, id uuid.UUID) (*domain.Product, error) {
+	var product domain.Product
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&product).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// changeWarehouseStock применяет движение к остатку на складе и записывает его в журнал.
+// Если остаток стал бы отрицательным, возвращает domain.ErrInsufficientStock.
+func changeWarehouseStock(tx *gorm.DB, adjustment *domain.StockAdjustment) error {
+	var after int
+	if err := tx.Raw(`INSERT INTO warehouse_stocks (warehouse_id, product_id, quantity, updated_at)
+		VALUES (@warehouse, @product, @delta, @now)
+		ON CONFLICT (warehouse_id, product_id) DO UPDATE
+		SET quantity = warehouse_stocks.quantity + EXCLUDED.quantity, updated_at = EXCLUDED.updated_at
+		RETURNING quantity`, map[string]interface{}{
+		"warehouse": adjustment.WarehouseID,
+		"product":   adjustment.ProductID,
+		"delta":     adjustment.Delta,
+		"now":       time.Now(),
+	}).Scan(&after).Error; err != nil {
+		return err
+	}
+	if after < 0 {
+		return fmt.Errorf("%w: warehouse has %d of product %s, requested %d",
+			domain.ErrInsufficientStock, after-adjustment.Delta, adjustment.ProductID, -adjustment.Delta)
+	}
+
+	adjustment.QuantityAfter = after
+	return tx.Create(adjustment).Error
+}
+
+// drainStock списывает quantity со складов в порядке приоритета и возвращает записи журнала
+// по каждому складу. Если на складах меньше quantity, списывается все, что есть.
+func drainStock(tx *gorm.DB, productID uuid.UUID, quantity int, reason domain.StockAdjustmentReason, reference string) ([]*domain.StockAdjustment, error) {
+	var levels []domain.WarehouseStock
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "warehouse_stocks"}}).
+		Joins("JOIN warehouses ON warehouses.id = warehouse_stocks.warehouse_id").
+		Where("warehouse_stocks.product_id = ? AND warehouse_stocks.quantity > 0", productID).
+		Order("warehouses.priority, warehouses.code").
+		Find(&levels).Error; err != nil {
+		return nil, err
+	}
+
+	var adjustments []*domain.StockAdjustment
+	for _, level := range levels {
+		if quantity == 0 {
+			break
+		}
+		take := min(quantity, level.Quantity)
+		adjustment := domain.NewStockAdjustment(productID, level.WarehouseID, -take, reason, reference)
+		if err := changeWarehouseStock(tx, adjustment); err != nil {
+			return nil, err
+		}
+		adjustments = append(adjustments, adjustment)
+		quantity -= take
+	}
+
+	return adjustments, nil
+}
+
+// restock возвращает товар на склад warehouseID, а если он не указан или отключен — на основной склад
+func restock(tx *gorm.DB, warehouseID *uuid.UUID, productID uuid.UUID, quantity int, reason domain.StockAdjustmentReason, reference string) error {
+	if warehouseID != nil {
+		warehouse, err := findWarehouse(tx, *warehouseID)
+		if err != nil && !errors.Is(err, domain.ErrWarehouseNotFound) {
+			return err
+		}
+		if err == nil && warehouse.Active {
+			return changeWarehouseStock(tx, domain.NewStockAdjustment(productID, warehouse.ID, quantity, reason, reference))
+		}
+	}
+
+	var warehouse domain.Warehouse
+	if err := tx.Where("active").Order("priority, code").First(&warehouse).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrNoWarehouse
+		}
+		return err
+	}
+
+	return changeWarehouseStock(tx, domain.NewStockAdjustment(productID, warehouse.ID, quantity, reason, reference))
+}
+
+// moveStock распределяет изменение общего остатка товара по складам:
+// приход уходит на основной склад, расход списывается по приоритету складов
+func moveStock(tx *gorm.DB, productID uuid.UUID, delta int, reason domain.StockAdjustmentReason, reference string) error {
+	switch {
+	case delta > 0:
+		return restock(tx, nil, productID, delta, reason, reference)
+	case delta < 0:
+		_, err := drainStock(tx, productID, -delta, reason, reference)
+		return err
+	default:
+		return nil
+	}
+}
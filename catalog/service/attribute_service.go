@@ -0,0 +1,171 @@
+package service
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/repository"
+	"sort"
+)
+
+type AttributeService struct {
+	attributeRepo repository.AttributeRepository
+	productRepo   repository.ProductRepository
+	sellerRepo    repository.SellerRepository
+	audit         *AuditService
+	events        *EventPublisher
+}
+
+func NewAttributeService(attributeRepo repository.AttributeRepository, productRepo repository.ProductRepository, sellerRepo repository.SellerRepository, audit *AuditService, events *EventPublisher) *AttributeService {
+	return &AttributeService{
+		attributeRepo: attributeRepo,
+		productRepo:   productRepo,
+		sellerRepo:    sellerRepo,
+		audit:         audit,
+		events:        events,
+	}
+}
+
+func (s *AttributeService) CreateAttribute(code, name, attrType string, options []string, filterable bool) (*domain.AttributeDefinition, error) {
+	definition, err := domain.NewAttributeDefinition(code, name, domain.AttributeType(attrType), options, filterable)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attributeRepo.Create(definition); err != nil {
+		return nil, err
+	}
+
+	return definition, nil
+}
+
+func (s *AttributeService) GetAttributes() ([]*domain.AttributeDefinition, error) {
+	return s.attributeRepo.FindAll()
+}
+
+func (s *AttributeService) DeleteAttribute(code string) error {
+	return s.attributeRepo.Delete(code)
+}
+
+// SetProductAttributes заменяет характеристики товара с версией expectedVersion.
+// Значения проверяются по типам характеристик и приводятся к каноническому виду.
+func (s *AttributeService) SetProductAttributes(productID string, expectedVersion int, actor domain.Actor, values map[string]string) (*domain.Product, error) {
+	uid, err := uuid.Parse(productID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+
+	definitions, err := s.definitionsByCode()
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := make([]domain.ProductAttribute, 0, len(values))
+	for code, value := range values {
+		definition, ok := definitions[code]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown attribute %q", domain.ErrInvalidAttribute, code)
+		}
+		normalized, err := definition.NormalizeValue(value)
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, domain.ProductAttribute{ProductID: uid, Code: code, Value: normalized})
+	}
+	sort.Slice(attributes, func(i, j int) bool { return attributes[i].Code < attributes[j].Code })
+
+	product, err := s.productRepo.FindByID(uid)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeEdit(s.sellerRepo, product, actor); err != nil {
+		return nil, err
+	}
+	if product.Version != expectedVersion {
+		return nil, domain.ErrVersionConflict
+	}
+
+	before := *product
+	if err := s.attributeRepo.SetProductAttributes(product, attributes); err != nil {
+		return nil, err
+	}
+	s.audit.Record(domain.AuditUpdated, actor.Email, &before, product)
+	s.events.ProductUpdated(&before, product)
+
+	return product, nil
+}
+
+// ParseFilter проверяет фильтр attr[code]=value и приводит значения к каноническому виду
+func (s *AttributeService) ParseFilter(raw map[string][]string) (map[string][]string, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	definitions, err := s.definitionsByCode()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := make(map[string][]string, len(raw))
+	for code, values := range raw {
+		definition, ok := definitions[code]
+		if !ok || !definition.Filterable {
+			return nil, fmt.Errorf("%w: attribute %q is not filterable", domain.ErrInvalidAttribute, code)
+		}
+		for _, value := range values {
+			normalized, err := definition.NormalizeValue(value)
+			if err != nil {
+				return nil, err
+			}
+			filter[code] = append(filter[code], normalized)
+		}
+	}
+
+	return filter, nil
+}
+
+// GetFacets считает значения фильтруемых характеристик среди публичных товаров,
+// подходящих под фильтр. Условие по самой характеристике при подсчете не учитывается,
+// чтобы витрина могла показать альтернативы уже выбранному значению.
+func (s *AttributeService) GetFacets(attributes map[string][]string) ([]*domain.AttributeFacet, error) {
+	definitions, err := s.attributeRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := domain.PublicProductFilter()
+	filter.Attributes = attributes
+
+	facets := make([]*domain.AttributeFacet, 0, len(definitions))
+	for _, definition := range definitions {
+		if !definition.Filterable {
+			continue
+		}
+		values, err := s.attributeRepo.CountValues(filter.WithoutAttribute(definition.Code), definition.Code)
+		if err != nil {
+			return nil, err
+		}
+		facets = append(facets, &domain.AttributeFacet{
+			Code:   definition.Code,
+			Name:   definition.Name,
+			Type:   definition.Type,
+			Values: values,
+		})
+	}
+
+	return facets, nil
+}
+
+func (s *AttributeService) definitionsByCode() (map[string]*domain.AttributeDefinition, error) {
+	definitions, err := s.attributeRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	byCode := make(map[string]*domain.AttributeDefinition, len(definitions))
+	for _, definition := range definitions {
+		byCode[definition.Code] = definition
+	}
+
+	return byCode, nil
+}
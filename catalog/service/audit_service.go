@@ -0,0 +1,57 @@
+package service
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/repository"
+	"log"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+type AuditService struct {
+	auditRepo repository.AuditRepository
+}
+
+func NewAuditService(auditRepo repository.AuditRepository) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+// Record записывает изменение товара в журнал. Как и история цен, журнал не отменяет
+// уже сделанное изменение: ошибка записи только логируется.
+func (s *AuditService) Record(action domain.AuditAction, actor string, before, after *domain.Product) {
+	record := domain.NewAuditRecord(action, domain.NormalizeEmail(actor), before, after)
+	if record == nil {
+		return
+	}
+
+	if err := s.auditRepo.Append(record); err != nil {
+		log.Printf("Failed to record %s audit for product %s: %v", action, record.ProductID, err)
+	}
+}
+
+// GetAuditRecords возвращает журнал изменений, новые записи первыми.
+// Пустые productID, actor и action не ограничивают выборку.
+func (s *AuditService) GetAuditRecords(productID, actor, action string, limit int) ([]*domain.AuditRecord, error) {
+	filter := domain.AuditFilter{Actor: domain.NormalizeEmail(actor), Action: domain.AuditAction(action)}
+	if productID != "" {
+		uid, err := uuid.Parse(productID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+		}
+		filter.ProductID = uid
+	}
+
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+	if limit > maxAuditLimit {
+		limit = maxAuditLimit
+	}
+
+	return s.auditRepo.Find(filter, limit)
+}
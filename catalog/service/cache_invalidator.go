@@ -0,0 +1,37 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/kafka"
+	"github.com/yangirxd/store-app/catalog/repository"
+)
+
+// ProductCacheTopics — события, после которых закэшированный товар считается устаревшим
+var ProductCacheTopics = []string{
+	domain.TopicProductCreated,
+	domain.TopicProductUpdated,
+	domain.TopicProductDeleted,
+	domain.TopicProductStockChanged,
+}
+
+// CacheInvalidator сбрасывает кэш товаров по событиям каталога. Каждая реплика читает
+// события своей группой, поэтому изменение на одной реплике сбрасывает кэш на всех.
+type CacheInvalidator struct {
+	cache *repository.CachedProductRepository
+}
+
+func NewCacheInvalidator(cache *repository.CachedProductRepository) *CacheInvalidator {
+	return &CacheInvalidator{cache: cache}
+}
+
+func (i *CacheInvalidator) ProcessProductEvent(data []byte) error {
+	var event domain.ProductEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return fmt.Errorf("%w: failed to unmarshal product event: %v", kafka.ErrMalformedMessage, err)
+	}
+
+	i.cache.Invalidate(event.ProductID)
+	return nil
+}
@@ -1,73 +1,404 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/yangirxd/store-app/catalog/domain"
 	"github.com/yangirxd/store-app/catalog/repository"
+	"strings"
 )
 
 type CatalogService struct {
-	productRepo repository.ProductRepository
+	productRepo  repository.ProductRepository
+	bundleRepo   repository.BundleRepository
+	sellerRepo   repository.SellerRepository
+	priceHistory *PriceHistoryService
+	audit        *AuditService
+	events       *EventPublisher
 }
 
-func NewCatalogService(productRepo repository.ProductRepository) *CatalogService {
-	return &CatalogService{productRepo: productRepo}
+func NewCatalogService(productRepo repository.ProductRepository, bundleRepo repository.BundleRepository, sellerRepo repository.SellerRepository, priceHistory *PriceHistoryService, audit *AuditService, events *EventPublisher) *CatalogService {
+	return &CatalogService{
+		productRepo:  productRepo,
+		bundleRepo:   bundleRepo,
+		sellerRepo:   sellerRepo,
+		priceHistory: priceHistory,
+		audit:        audit,
+		events:       events,
+	}
 }
 
-func (s *CatalogService) CreateProduct(name, description string, price float64, stock int) (*domain.Product, error) {
+// CreateProduct создает товар от имени продавца sellerEmail
+func (s *CatalogService) CreateProduct(sku, name, description string, price float64, stock int, status, taxClass, sellerEmail string) (*domain.Product, error) {
 	product, err := domain.NewProduct(name, description, price, stock)
 	if err != nil {
 		return nil, err
 	}
+	seller, err := s.sellerRepo.FindOrCreate(sellerEmail)
+	if err != nil {
+		return nil, err
+	}
+	product.SellerID = &seller.ID
+	product.SKU = strings.TrimSpace(sku)
+	if status != "" {
+		if product.Status, err = domain.ParseProductStatus(status); err != nil {
+			return nil, err
+		}
+	}
+	if taxClass != "" {
+		if product.TaxClass, err = domain.ParseTaxClass(taxClass); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := s.productRepo.Create(product); err != nil {
 		return nil, err
 	}
+	s.audit.Record(domain.AuditCreated, sellerEmail, nil, product)
+	s.events.ProductCreated(product)
 
 	return product, nil
 }
 
+// GetProductByID возвращает товар из публичного каталога: черновики и архив не видны
 func (s *CatalogService) GetProductByID(id string) (*domain.Product, error) {
+	product, err := s.GetProductByIDForAdmin(id)
+	if err != nil {
+		return nil, err
+	}
+	if !product.IsPublic() {
+		return nil, domain.ErrProductNotFound
+	}
+
+	return product, nil
+}
+
+// GetProductByIDForAdmin возвращает товар в любом статусе
+func (s *CatalogService) GetProductByIDForAdmin(id string) (*domain.Product, error) {
 	uid, err := uuid.Parse(id)
 	if err != nil {
-		return nil, fmt.Errorf("invalid UUID: %v", err)
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+
+	product, err := s.productRepo.FindByID(uid)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillBundleStock([]*domain.Product{product}); err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+// GetProductBySlug возвращает публичный товар по текущему или прежнему slug.
+// Если product.Slug отличается от запрошенного, slug устарел.
+func (s *CatalogService) GetProductBySlug(slug string) (*domain.Product, error) {
+	product, err := s.productRepo.FindBySlug(strings.ToLower(slug))
+	if err != nil {
+		return nil, err
+	}
+	if !product.IsPublic() {
+		return nil, domain.ErrProductNotFound
+	}
+	if err := s.fillBundleStock([]*domain.Product{product}); err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+// GetAllProducts возвращает публичные товары; attributes — уже проверенный фильтр по характеристикам
+func (s *CatalogService) GetAllProducts(attributes map[string][]string) ([]*domain.Product, error) {
+	filter := domain.PublicProductFilter()
+	filter.Attributes = attributes
+
+	return s.findAll(filter)
+}
+
+// MaxBatchGetSize ограничивает число ID в одном пакетном запросе
+const MaxBatchGetSize = 100
+
+// GetProductsByIDs возвращает товары по списку ID и ID, которые не найдены.
+// Пользователям видны только публичные товары; другим сервисам — товары в любом статусе,
+// кроме удаленных, чтобы, например, orders отличал снятый с продажи товар от несуществующего.
+// Повторяющиеся ID учитываются один раз, порядок результата совпадает с порядком запроса.
+func (s *CatalogService) GetProductsByIDs(ids []string, actor domain.Actor) ([]*domain.Product, []string, error) {
+	if len(ids) > MaxBatchGetSize {
+		return nil, nil, fmt.Errorf("at most %d IDs are allowed", MaxBatchGetSize)
+	}
+
+	uids := make([]uuid.UUID, 0, len(ids))
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		uid, err := uuid.Parse(id)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid UUID %q: %v", id, err)
+		}
+		if !seen[uid] {
+			seen[uid] = true
+			uids = append(uids, uid)
+		}
+	}
+
+	filter := domain.PublicProductFilter()
+	if actor.IsService {
+		filter = domain.ProductFilter{}
+	}
+	products, err := s.findByIDs(uids, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	found := make(map[uuid.UUID]bool, len(products))
+	for _, product := range products {
+		found[product.ID] = true
 	}
 
-	return s.productRepo.FindByID(uid)
+	missing := make([]string, 0)
+	for _, uid := range uids {
+		if !found[uid] {
+			missing = append(missing, uid.String())
+		}
+	}
+
+	return products, missing, nil
 }
 
-func (s *CatalogService) GetAllProducts() ([]*domain.Product, error) {
-	return s.productRepo.FindAll()
+// findPublicByIDs возвращает публичные товары из ids в порядке ids, пропуская ненайденные
+func (s *CatalogService) findPublicByIDs(ids []uuid.UUID) ([]*domain.Product, error) {
+	return s.findByIDs(ids, domain.PublicProductFilter())
 }
 
-func (s *CatalogService) UpdateProduct(id, name, description string, price float64, stock int) (*domain.Product, error) {
+// findByIDs возвращает подходящие под filter товары из ids в порядке ids, пропуская ненайденные
+func (s *CatalogService) findByIDs(ids []uuid.UUID, filter domain.ProductFilter) ([]*domain.Product, error) {
+	found, err := s.productRepo.FindByIDs(ids, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillBundleStock(found); err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*domain.Product, len(found))
+	for _, product := range found {
+		byID[product.ID] = product
+	}
+
+	products := make([]*domain.Product, 0, len(found))
+	for _, id := range ids {
+		if product, ok := byID[id]; ok {
+			products = append(products, product)
+		}
+	}
+
+	return products, nil
+}
+
+// GetSellerProducts возвращает витрину продавца: его публичные товары
+func (s *CatalogService) GetSellerProducts(sellerID string) ([]*domain.Product, error) {
+	uid, err := uuid.Parse(sellerID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+	if _, err := s.sellerRepo.FindByID(uid); err != nil {
+		return nil, err
+	}
+
+	filter := domain.PublicProductFilter()
+	filter.SellerID = uid
+	return s.findAll(filter)
+}
+
+// GetProductsForAdmin возвращает товары в указанных статусах (во всех, если не указаны)
+func (s *CatalogService) GetProductsForAdmin(statuses []string, includeDeleted bool) ([]*domain.Product, error) {
+	filter := domain.ProductFilter{IncludeDeleted: includeDeleted}
+	for _, status := range statuses {
+		parsed, err := domain.ParseProductStatus(status)
+		if err != nil {
+			return nil, err
+		}
+		filter.Statuses = append(filter.Statuses, parsed)
+	}
+
+	return s.findAll(filter)
+}
+
+func (s *CatalogService) findAll(filter domain.ProductFilter) ([]*domain.Product, error) {
+	products, err := s.productRepo.FindAll(filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillBundleStock(products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// UpdateProduct изменяет товар, если его текущая версия равна expectedVersion
+func (s *CatalogService) UpdateProduct(id string, expectedVersion int, actor domain.Actor, name, description string, price float64, stock int) (*domain.Product, error) {
+	return s.PatchProduct(id, expectedVersion, actor, &domain.ProductPatch{
+		Name:        &name,
+		Description: &description,
+		Price:       &price,
+		Stock:       &stock,
+	})
+}
+
+// PatchProduct применяет частичное изменение к товару с версией expectedVersion
+func (s *CatalogService) PatchProduct(id string, expectedVersion int, actor domain.Actor, patch domain.Patch) (*domain.Product, error) {
 	uid, err := uuid.Parse(id)
 	if err != nil {
-		return nil, fmt.Errorf("invalid UUID: %v", err)
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
 	}
 
 	product, err := s.productRepo.FindByID(uid)
 	if err != nil {
 		return nil, err
 	}
+	if err := authorizeEdit(s.sellerRepo, product, actor); err != nil {
+		return nil, err
+	}
+	if product.Version != expectedVersion {
+		return nil, domain.ErrVersionConflict
+	}
 
-	product.Name = name
-	product.Description = description
-	product.Price = price
-	product.Stock = stock
+	before := *product
+	if err := patch.Apply(product); err != nil {
+		return nil, err
+	}
 	if err := s.productRepo.Update(product); err != nil {
 		return nil, err
 	}
+	s.priceHistory.RecordPriceChange(&before, product, domain.PriceChangeManual)
+	s.audit.Record(domain.AuditUpdated, actor.Email, &before, product)
+	s.events.ProductUpdated(&before, product)
+	if err := s.fillBundleStock([]*domain.Product{product}); err != nil {
+		return nil, err
+	}
 
 	return product, nil
 }
 
-func (s *CatalogService) DeleteProduct(id string) error {
+// DeleteProduct удаляет товар, если его текущая версия равна expectedVersion
+func (s *CatalogService) DeleteProduct(id string, expectedVersion int, actor domain.Actor) error {
 	uid, err := uuid.Parse(id)
 	if err != nil {
-		return fmt.Errorf("invalid UUID: %v", err)
+		return fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+
+	product, err := s.productRepo.FindByID(uid)
+	if err != nil {
+		return err
+	}
+	if err := authorizeEdit(s.sellerRepo, product, actor); err != nil {
+		return err
+	}
+
+	if err := s.productRepo.Delete(uid, expectedVersion); err != nil {
+		return err
+	}
+	s.audit.Record(domain.AuditDeleted, actor.Email, product, nil)
+	s.events.ProductDeleted(uid)
+
+	return nil
+}
+
+// RestoreProduct возвращает мягко удаленный товар
+func (s *CatalogService) RestoreProduct(id string, actor domain.Actor) (*domain.Product, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+
+	product, err := s.productRepo.Restore(uid)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(domain.AuditRestored, actor.Email, product, product)
+	s.events.ProductRestored(product)
+	if err := s.fillBundleStock([]*domain.Product{product}); err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+// SetBundleComponents заменяет состав набора, если версия товара равна expectedVersion.
+// Пустой состав превращает набор обратно в обычный товар.
+func (s *CatalogService) SetBundleComponents(id string, expectedVersion int, actor domain.Actor, components []domain.BundleComponent) (*domain.Product, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+	components, err = domain.NewBundleComponents(uid, components)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := s.productRepo.FindByID(uid)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeEdit(s.sellerRepo, product, actor); err != nil {
+		return nil, err
+	}
+	if product.Version != expectedVersion {
+		return nil, domain.ErrVersionConflict
+	}
+
+	before := *product
+	if err := s.bundleRepo.SetComponents(product, components); err != nil {
+		return nil, err
+	}
+	s.audit.Record(domain.AuditUpdated, actor.Email, &before, product)
+	s.events.ProductChanged(uid, "components")
+	if err := s.fillBundleStock([]*domain.Product{product}); err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+// fillBundleStock подставляет в наборы остаток, вычисленный по компонентам.
+// Он считается при каждом чтении: кэш товаров не знает об изменениях остатков компонентов.
+func (s *CatalogService) fillBundleStock(products []*domain.Product) error {
+	var ids []uuid.UUID
+	for _, product := range products {
+		if product.IsBundle() {
+			ids = append(ids, product.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	availability, err := s.bundleRepo.FindAvailability(ids)
+	if err != nil {
+		return err
+	}
+	for _, product := range products {
+		if product.IsBundle() {
+			product.Stock = availability[product.ID]
+		}
+	}
+
+	return nil
+}
+
+// authorizeEdit проверяет, что actor может менять товар, иначе возвращает domain.ErrNotProductOwner
+func authorizeEdit(sellerRepo repository.SellerRepository, product *domain.Product, actor domain.Actor) error {
+	if actor.IsAdmin {
+		return nil
+	}
+
+	seller, err := sellerRepo.FindByEmail(actor.Email)
+	if err != nil && !errors.Is(err, domain.ErrSellerNotFound) {
+		return err
+	}
+	if !product.CanEdit(actor, seller) {
+		return domain.ErrNotProductOwner
 	}
 
-	return s.productRepo.Delete(uid)
+	return nil
 }
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/kafka"
+	"github.com/yangirxd/store-app/catalog/repository"
+	"log"
+	"slices"
+	"time"
+)
+
+const (
+	eventQueueSize     = 10000
+	eventBatchSize     = 100
+	minPublishBackoff  = time.Second
+	maxPublishBackoff  = time.Minute
+	backInStockTimeout = 5 * time.Second
+)
+
+// EventPublisher публикует события каталога в Kafka. События об изменениях ставятся в очередь
+// и отправляются фоном из Run, поэтому запрос не ждет брокер: изменение уже сохранено,
+// и откатывать его из-за брокера нельзя. Пока брокер недоступен, Run повторяет отправку;
+// события теряются только при переполнении очереди или остановке процесса.
+type EventPublisher struct {
+	kafkaProducer *kafka.Producer
+	cache         *repository.CachedProductRepository
+	queue         chan kafka.Message
+}
+
+// NewEventPublisher принимает кэш товаров этой реплики: события product.* сбрасывают его сразу,
+// потому что изменения характеристик, изображений, переводов, отзывов и остатков идут мимо кэша
+func NewEventPublisher(kafkaProducer *kafka.Producer, cache *repository.CachedProductRepository) *EventPublisher {
+	return &EventPublisher{
+		kafkaProducer: kafkaProducer,
+		cache:         cache,
+		queue:         make(chan kafka.Message, eventQueueSize),
+	}
+}
+
+// Run отправляет события из очереди пачками в порядке публикации до отмены ctx
+func (p *EventPublisher) Run(ctx context.Context) {
+	for {
+		var batch []kafka.Message
+		select {
+		case <-ctx.Done():
+			return
+		case message := <-p.queue:
+			batch = append(batch, message)
+		}
+	collect:
+		for len(batch) < eventBatchSize {
+			select {
+			case message := <-p.queue:
+				batch = append(batch, message)
+			default:
+				break collect
+			}
+		}
+
+		p.deliver(ctx, batch)
+	}
+}
+
+// deliver повторяет отправку пачки с растущей паузой, пока брокер ее не примет
+func (p *EventPublisher) deliver(ctx context.Context, batch []kafka.Message) {
+	backoff := minPublishBackoff
+	for {
+		err := p.kafkaProducer.ProduceMessages(ctx, batch)
+		if err == nil {
+			return
+		}
+		log.Printf("Failed to produce %d events, retrying in %s: %v", len(batch), backoff, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxPublishBackoff)
+	}
+}
+
+func (p *EventPublisher) ProductCreated(product *domain.Product) {
+	event := domain.NewProductEvent(domain.TopicProductCreated, product.ID)
+	event.Product = product
+	p.publish(domain.TopicProductCreated, product.ID, event)
+}
+
+// ProductUpdated публикует product.updated и, если изменился остаток, product.stock_changed
+func (p *EventPublisher) ProductUpdated(before, after *domain.Product) {
+	fields := domain.ChangedFields(before, after)
+	if len(fields) == 0 {
+		return
+	}
+
+	event := domain.NewProductEvent(domain.TopicProductUpdated, after.ID)
+	event.Product = after
+	event.ChangedFields = fields
+	if before.Price != after.Price {
+		event.OldPrice = &before.Price
+		event.NewPrice = &after.Price
+	}
+	p.publish(domain.TopicProductUpdated, after.ID, event)
+
+	if before.Stock != after.Stock {
+		p.StockChanged([]domain.StockChange{{ProductID: after.ID, Before: before.Stock, After: after.Stock}})
+	}
+}
+
+func (p *EventPublisher) ProductRestored(product *domain.Product) {
+	event := domain.NewProductEvent(domain.TopicProductUpdated, product.ID)
+	event.Product = product
+	event.ChangedFields = []string{"deletedAt"}
+	p.publish(domain.TopicProductUpdated, product.ID, event)
+}
+
+// ProductChanged публикует product.updated для изменений связанных данных (изображений, отзывов),
+// которые не проходят через карточку товара, но меняют ее представление
+func (p *EventPublisher) ProductChanged(productID uuid.UUID, fields ...string) {
+	event := domain.NewProductEvent(domain.TopicProductUpdated, productID)
+	event.ChangedFields = fields
+	p.publish(domain.TopicProductUpdated, productID, event)
+}
+
+func (p *EventPublisher) ProductDeleted(productID uuid.UUID) {
+	p.publish(domain.TopicProductDeleted, productID, domain.NewProductEvent(domain.TopicProductDeleted, productID))
+}
+
+func (p *EventPublisher) StockChanged(changes []domain.StockChange) {
+	for _, change := range changes {
+		if change.Before == change.After {
+			continue
+		}
+		event := domain.NewProductEvent(domain.TopicProductStockChanged, change.ProductID)
+		event.ChangedFields = []string{"stock"}
+		event.OldStock = &change.Before
+		event.NewStock = &change.After
+		p.publish(domain.TopicProductStockChanged, change.ProductID, event)
+	}
+}
+
+func (p *EventPublisher) InventoryAlert(topic string, alert *domain.InventoryAlertEvent) {
+	p.publish(topic, alert.ProductID, alert)
+}
+
+// BackInStock публикует событие сразу и возвращает ошибку: по нему уведомляют подписчиков,
+// и подписки удаляются только после успешной отправки
+func (p *EventPublisher) BackInStock(event *domain.BackInStockEvent) error {
+	eventData, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), backInStockTimeout)
+	defer cancel()
+	return p.kafkaProducer.Produce(ctx, domain.TopicProductBackInStock, []byte(event.ProductID.String()), eventData)
+}
+
+// publish ставит событие в очередь на отправку
+func (p *EventPublisher) publish(topic string, productID uuid.UUID, event interface{}) {
+	// Своя реплика не ждет события из Kafka, остальные сбросят кэш через CacheInvalidator
+	if slices.Contains(ProductCacheTopics, topic) {
+		p.cache.Invalidate(productID)
+	}
+
+	eventData, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to marshal %s event: %v", topic, err)
+		return
+	}
+	select {
+	case p.queue <- kafka.Message{Topic: topic, Key: []byte(productID.String()), Value: eventData}:
+	default:
+		log.Printf("Event queue is full, dropping %s event for product %s", topic, productID)
+	}
+}
@@ -0,0 +1,395 @@
+package service
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/repository"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	importProgressEvery = 500
+	exportBatchSize     = 500
+)
+
+var productCSVHeader = []string{"sku", "name", "description", "price", "stock", "status"}
+
+type ImportService struct {
+	productRepo  repository.ProductRepository
+	sellerRepo   repository.SellerRepository
+	jobRepo      repository.ImportJobRepository
+	priceHistory *PriceHistoryService
+	audit        *AuditService
+	events       *EventPublisher
+}
+
+func NewImportService(productRepo repository.ProductRepository, sellerRepo repository.SellerRepository, jobRepo repository.ImportJobRepository, priceHistory *PriceHistoryService, audit *AuditService, events *EventPublisher) *ImportService {
+	return &ImportService{
+		productRepo:  productRepo,
+		sellerRepo:   sellerRepo,
+		jobRepo:      jobRepo,
+		priceHistory: priceHistory,
+		audit:        audit,
+		events:       events,
+	}
+}
+
+// StartImport сохраняет тело запроса во временный файл и запускает импорт в фоне.
+// Файл нужен, потому что тело запроса недоступно после ответа клиенту.
+func (s *ImportService) StartImport(format string, dryRun bool, createdBy string, body io.Reader) (*domain.ImportJob, error) {
+	importFormat, err := domain.ParseImportFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.CreateTemp("", "catalog-import-*")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(file, body); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return nil, err
+	}
+
+	job := domain.NewImportJob(importFormat, dryRun, createdBy)
+	if err := s.jobRepo.Create(job); err != nil {
+		os.Remove(file.Name())
+		return nil, err
+	}
+
+	go s.runImport(job, file.Name())
+
+	return job, nil
+}
+
+// GetImportJob возвращает задание импорта, если actor может его видеть
+func (s *ImportService) GetImportJob(id string, actor domain.Actor) (*domain.ImportJob, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+
+	job, err := s.jobRepo.FindByID(uid)
+	if err != nil {
+		return nil, err
+	}
+	if !job.CanView(actor) {
+		return nil, domain.ErrImportJobForbidden
+	}
+
+	return job, nil
+}
+
+func (s *ImportService) runImport(job *domain.ImportJob, path string) {
+	defer os.Remove(path)
+
+	job.Status = domain.ImportRunning
+	s.saveJob(job)
+
+	file, err := os.Open(path)
+	if err != nil {
+		job.Finish(domain.ImportFailed, err.Error())
+		s.saveJob(job)
+		return
+	}
+	defer file.Close()
+
+	// Новые товары записываются на автора импорта; чужие товары он, как и через API, не меняет
+	actor := domain.Actor{Email: job.CreatedBy, IsAdmin: domain.IsAdmin(job.CreatedBy)}
+	seller, err := s.sellerRepo.FindOrCreate(job.CreatedBy)
+	if err != nil {
+		job.Finish(domain.ImportFailed, err.Error())
+		s.saveJob(job)
+		return
+	}
+
+	err = readProductRows(job.Format, file, func(row int, productRow *domain.ProductRow, rowErr error) {
+		job.TotalRows++
+		if rowErr == nil {
+			rowErr = s.importRow(job, seller, actor, productRow)
+		}
+		if rowErr != nil {
+			sku := ""
+			if productRow != nil {
+				sku = productRow.SKU
+			}
+			job.AddError(row, sku, rowErr.Error())
+		}
+		if job.TotalRows%importProgressEvery == 0 {
+			s.saveJob(job)
+		}
+	})
+	if err != nil {
+		job.Finish(domain.ImportFailed, err.Error())
+	} else {
+		job.Finish(domain.ImportCompleted, "")
+	}
+	s.saveJob(job)
+}
+
+// importRow создает товар или обновляет существующий с тем же SKU
+func (s *ImportService) importRow(job *domain.ImportJob, seller *domain.Seller, actor domain.Actor, row *domain.ProductRow) error {
+	product, err := row.ToProduct()
+	if err != nil {
+		return err
+	}
+	product.SellerID = &seller.ID
+
+	existing, err := s.productRepo.FindBySKU(product.SKU)
+	if errors.Is(err, domain.ErrProductNotFound) {
+		if !job.DryRun {
+			if err := s.productRepo.Create(product); err != nil {
+				return err
+			}
+			s.audit.Record(domain.AuditCreated, job.CreatedBy, nil, product)
+			s.events.ProductCreated(product)
+		}
+		job.CreatedRows++
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !existing.CanEdit(actor, seller) {
+		return domain.ErrNotProductOwner
+	}
+	if existing.DeletedAt.Valid {
+		return fmt.Errorf("%w: sku %s belongs to a deleted product, restore it before importing", domain.ErrInvalidProduct, existing.SKU)
+	}
+
+	// Кроме обязательных названия и цены файл может содержать только часть колонок: остальное не трогаем
+	before := *existing
+	existing.Name = product.Name
+	existing.Price = product.Price
+	if row.Has("description") {
+		existing.Description = product.Description
+	}
+	if row.Has("stock") {
+		existing.Stock = product.Stock
+	}
+	if row.Has("status") && row.Status != "" {
+		existing.Status = product.Status
+	}
+	if !job.DryRun {
+		if err := s.productRepo.Update(existing); err != nil {
+			return err
+		}
+		s.priceHistory.RecordPriceChange(&before, existing, domain.PriceChangeImport)
+		s.audit.Record(domain.AuditUpdated, job.CreatedBy, &before, existing)
+		s.events.ProductUpdated(&before, existing)
+	}
+	job.UpdatedRows++
+	return nil
+}
+
+func (s *ImportService) saveJob(job *domain.ImportJob) {
+	if err := s.jobRepo.Update(job); err != nil {
+		log.Printf("Failed to save import job %s: %v", job.ID, err)
+	}
+}
+
+// readProductRows вызывает fn для каждой строки файла. Ошибка разбора строки передается в fn,
+// а ошибка всего файла (например, неверный заголовок CSV) возвращается.
+func readProductRows(format domain.ImportFormat, r io.Reader, fn func(row int, productRow *domain.ProductRow, err error)) error {
+	switch format {
+	case domain.FormatCSV:
+		return readCSVRows(r, fn)
+	case domain.FormatNDJSON:
+		return readNDJSONRows(r, fn)
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func readCSVRows(r io.Reader, fn func(row int, productRow *domain.ProductRow, err error)) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read CSV header: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range requiredImportFields {
+		if _, ok := columns[required]; !ok {
+			return fmt.Errorf("CSV header must contain %q column", required)
+		}
+	}
+	fields := make(map[string]bool, len(columns))
+	for column := range columns {
+		fields[column] = true
+	}
+
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				fn(row, nil, err)
+				continue
+			}
+			return err
+		}
+
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		productRow := &domain.ProductRow{
+			SKU:         value("sku"),
+			Name:        value("name"),
+			Description: value("description"),
+			Status:      value("status"),
+			Fields:      fields,
+		}
+		if productRow.Price, err = strconv.ParseFloat(value("price"), 64); err != nil {
+			fn(row, productRow, fmt.Errorf("invalid price %q", value("price")))
+			continue
+		}
+		if stock := value("stock"); stock != "" {
+			if productRow.Stock, err = strconv.Atoi(stock); err != nil {
+				fn(row, productRow, fmt.Errorf("invalid stock %q", stock))
+				continue
+			}
+		}
+		fn(row, productRow, nil)
+	}
+}
+
+// requiredImportFields есть в каждой строке: название и цена всегда перезаписываются,
+// поэтому строка без них обнулила бы цену или не прошла бы проверку товара
+var requiredImportFields = []string{"sku", "name", "price"}
+
+func missingImportField(fields map[string]bool) string {
+	for _, required := range requiredImportFields {
+		if !fields[required] {
+			return required
+		}
+	}
+
+	return ""
+}
+
+func readNDJSONRows(r io.Reader, fn func(row int, productRow *domain.ProductRow, err error)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for row := 1; scanner.Scan(); row++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var productRow domain.ProductRow
+		if err := json.Unmarshal([]byte(line), &productRow); err != nil {
+			fn(row, nil, fmt.Errorf("invalid JSON: %v", err))
+			continue
+		}
+		var keys map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(line), &keys); err != nil {
+			fn(row, &productRow, fmt.Errorf("invalid JSON: %v", err))
+			continue
+		}
+		productRow.Fields = make(map[string]bool, len(keys))
+		for key := range keys {
+			productRow.Fields[strings.ToLower(key)] = true
+		}
+		if missing := missingImportField(productRow.Fields); missing != "" {
+			fn(row, &productRow, fmt.Errorf("row must contain %q field", missing))
+			continue
+		}
+		fn(row, &productRow, nil)
+	}
+
+	return scanner.Err()
+}
+
+// ExportProducts пишет неудаленные товары в w, читая каталог порциями.
+// Администратор выгружает весь каталог, продавец — только свои товары.
+func (s *ImportService) ExportProducts(format string, actor domain.Actor, w io.Writer) error {
+	exportFormat, err := domain.ParseImportFormat(format)
+	if err != nil {
+		return err
+	}
+
+	var filter domain.ProductFilter
+	eachBatch := func(fn func(products []*domain.Product) error) error {
+		return s.productRepo.FindInBatches(filter, exportBatchSize, fn)
+	}
+	if !actor.IsAdmin {
+		seller, err := s.sellerRepo.FindByEmail(actor.Email)
+		switch {
+		case errors.Is(err, domain.ErrSellerNotFound):
+			// Пользователь еще ничего не выставлял: выгружать нечего
+			eachBatch = func(func(products []*domain.Product) error) error { return nil }
+		case err != nil:
+			return err
+		default:
+			filter.SellerID = seller.ID
+		}
+	}
+
+	switch exportFormat {
+	case domain.FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(productCSVHeader); err != nil {
+			return err
+		}
+		err = eachBatch(func(products []*domain.Product) error {
+			for _, product := range products {
+				row := domain.NewProductRow(product)
+				if err := writer.Write([]string{
+					row.SKU,
+					row.Name,
+					row.Description,
+					strconv.FormatFloat(row.Price, 'f', -1, 64),
+					strconv.Itoa(row.Stock),
+					row.Status,
+				}); err != nil {
+					return err
+				}
+			}
+			writer.Flush()
+			return writer.Error()
+		})
+		if err != nil {
+			return err
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		encoder := json.NewEncoder(w)
+		return eachBatch(func(products []*domain.Product) error {
+			for _, product := range products {
+				if err := encoder.Encode(domain.NewProductRow(product)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+}
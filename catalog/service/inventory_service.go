@@ -0,0 +1,447 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/kafka"
+	"github.com/yangirxd/store-app/catalog/repository"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultReservationTTL = 15 * time.Minute
+	MaxReservationTTL     = 24 * time.Hour
+
+	// Пользователь держит остатки только на время оформления заказа: иначе любой вошедший
+	// мог бы надолго занять весь склад. На сервисы ограничения не распространяются.
+	MaxUserReservationTTL      = 15 * time.Minute
+	MaxUserReservationQuantity = 10
+	MaxUserActiveReservations  = 3
+
+	reservationSweepBatch = 100
+
+	defaultAdjustmentLimit = 100
+	maxAdjustmentLimit     = 1000
+)
+
+type ReservationItem struct {
+	ProductID uuid.UUID
+	Quantity  int
+}
+
+type InventoryService struct {
+	reservationRepo   repository.ReservationRepository
+	inventoryRepo     repository.InventoryRepository
+	warehouseRepo     repository.WarehouseRepository
+	bundleRepo        repository.BundleRepository
+	events            *EventPublisher
+	lowStockThreshold int
+}
+
+func NewInventoryService(reservationRepo repository.ReservationRepository, inventoryRepo repository.InventoryRepository, warehouseRepo repository.WarehouseRepository, bundleRepo repository.BundleRepository, events *EventPublisher, lowStockThreshold int) *InventoryService {
+	return &InventoryService{
+		reservationRepo:   reservationRepo,
+		inventoryRepo:     inventoryRepo,
+		warehouseRepo:     warehouseRepo,
+		bundleRepo:        bundleRepo,
+		events:            events,
+		lowStockThreshold: lowStockThreshold,
+	}
+}
+
+// ReserveStock резервирует остатки от имени actor; резерв, созданный пользователем, доступен только ему
+func (s *InventoryService) ReserveStock(referenceID string, ttl time.Duration, items []ReservationItem, actor domain.Actor) (*domain.Reservation, error) {
+	if ttl <= 0 {
+		ttl = DefaultReservationTTL
+	}
+	if ttl > MaxReservationTTL {
+		ttl = MaxReservationTTL
+	}
+	if !actor.IsService {
+		if err := s.checkUserReservationLimits(items, actor); err != nil {
+			return nil, err
+		}
+		ttl = min(ttl, MaxUserReservationTTL)
+	}
+
+	reservation, err := domain.NewReservation(referenceID, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.IsService {
+		reservation.CreatedBy = domain.NormalizeEmail(actor.Email)
+	}
+	deltas := make([]domain.StockDelta, 0, len(items))
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity must be positive", domain.ErrInvalidReservation)
+		}
+		deltas = append(deltas, domain.StockDelta{ProductID: item.ProductID, Delta: item.Quantity})
+	}
+	// Резервируются компоненты наборов: своего остатка у набора нет
+	if deltas, err = s.expandBundles(deltas); err != nil {
+		return nil, err
+	}
+	for _, delta := range deltas {
+		if err := reservation.AddItem(delta.ProductID, delta.Delta); err != nil {
+			return nil, err
+		}
+	}
+
+	changes, err := s.reservationRepo.Reserve(reservation)
+	if err != nil {
+		return nil, err
+	}
+	s.stockChanged(changes)
+
+	return reservation, nil
+}
+
+// checkUserReservationLimits ограничивает количество каждого товара в резерве пользователя
+// и число его активных резервов
+func (s *InventoryService) checkUserReservationLimits(items []ReservationItem, actor domain.Actor) error {
+	quantities := make(map[uuid.UUID]int, len(items))
+	for _, item := range items {
+		quantities[item.ProductID] += item.Quantity
+		if quantities[item.ProductID] > MaxUserReservationQuantity {
+			return fmt.Errorf("%w: at most %d of each product can be reserved", domain.ErrInvalidReservation, MaxUserReservationQuantity)
+		}
+	}
+
+	active, err := s.reservationRepo.CountActiveByCreator(domain.NormalizeEmail(actor.Email), time.Now())
+	if err != nil {
+		return err
+	}
+	if active >= MaxUserActiveReservations {
+		return fmt.Errorf("%w: at most %d reservations can be active at once", domain.ErrReservationLimit, MaxUserActiveReservations)
+	}
+
+	return nil
+}
+
+func (s *InventoryService) GetReservation(referenceID string, actor domain.Actor) (*domain.Reservation, error) {
+	reservation, err := s.reservationRepo.FindByReferenceID(referenceID)
+	if err != nil {
+		return nil, err
+	}
+	if !reservation.CanManage(actor) {
+		return nil, domain.ErrReservationForbidden
+	}
+
+	return reservation, nil
+}
+
+func (s *InventoryService) CommitReservation(referenceID string, actor domain.Actor) (*domain.Reservation, error) {
+	if _, err := s.GetReservation(referenceID, actor); err != nil {
+		return nil, err
+	}
+
+	return s.reservationRepo.Commit(referenceID)
+}
+
+func (s *InventoryService) ReleaseReservation(referenceID string, actor domain.Actor) (*domain.Reservation, error) {
+	if _, err := s.GetReservation(referenceID, actor); err != nil {
+		return nil, err
+	}
+
+	reservation, changes, err := s.reservationRepo.Release(referenceID)
+	if err != nil {
+		return nil, err
+	}
+	s.stockChanged(changes)
+
+	return reservation, nil
+}
+
+func (s *InventoryService) CreateWarehouse(code, name string, priority int) (*domain.Warehouse, error) {
+	warehouse, err := domain.NewWarehouse(code, name, priority)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.warehouseRepo.Create(warehouse); err != nil {
+		return nil, err
+	}
+
+	return warehouse, nil
+}
+
+func (s *InventoryService) GetWarehouses() ([]*domain.Warehouse, error) {
+	return s.warehouseRepo.FindAll()
+}
+
+// UpdateWarehouse меняет указанные поля склада; nil оставляет поле без изменений
+func (s *InventoryService) UpdateWarehouse(id string, name *string, priority *int, active *bool) (*domain.Warehouse, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+
+	warehouse, err := s.warehouseRepo.FindByID(uid)
+	if err != nil {
+		return nil, err
+	}
+	if name != nil {
+		if strings.TrimSpace(*name) == "" {
+			return nil, fmt.Errorf("%w: name is required", domain.ErrInvalidWarehouse)
+		}
+		warehouse.Name = strings.TrimSpace(*name)
+	}
+	if priority != nil {
+		warehouse.Priority = *priority
+	}
+	if active != nil {
+		warehouse.Active = *active
+	}
+
+	if err := s.warehouseRepo.Update(warehouse); err != nil {
+		return nil, err
+	}
+
+	return warehouse, nil
+}
+
+// GetProductStock возвращает остатки товара по складам и доступное к продаже количество
+func (s *InventoryService) GetProductStock(productID string) (*domain.ProductStock, error) {
+	uid, err := uuid.Parse(productID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+
+	levels, err := s.warehouseRepo.FindProductStock(uid)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewProductStock(uid, levels), nil
+}
+
+// AdjustStock корректирует остаток товара на складе с указанием причины
+func (s *InventoryService) AdjustStock(productID, warehouseID uuid.UUID, delta int, reason, note, actor string) (*domain.StockAdjustment, error) {
+	adjustmentReason, err := domain.ParseAdjustmentReason(reason)
+	if err != nil {
+		return nil, err
+	}
+	if delta == 0 {
+		return nil, fmt.Errorf("%w: delta cannot be zero", domain.ErrInvalidAdjustment)
+	}
+
+	adjustment := domain.NewStockAdjustment(productID, warehouseID, delta, adjustmentReason, "")
+	adjustment.Note = note
+	adjustment.Actor = actor
+	change, err := s.warehouseRepo.Adjust(adjustment)
+	if err != nil {
+		return nil, err
+	}
+	s.stockChanged([]domain.StockChange{change})
+
+	return adjustment, nil
+}
+
+// TransferStock перемещает товар между складами; возвращает записи журнала расхода и прихода
+func (s *InventoryService) TransferStock(productID, fromWarehouseID, toWarehouseID uuid.UUID, quantity int, note, actor string) ([]*domain.StockAdjustment, error) {
+	transfer, err := domain.NewStockTransfer(productID, fromWarehouseID, toWarehouseID, quantity, note, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.warehouseRepo.Transfer(transfer)
+}
+
+// GetStockAdjustments возвращает журнал движений остатков, новые записи первыми.
+// Пустые productID, warehouseID и reason не ограничивают выборку.
+func (s *InventoryService) GetStockAdjustments(productID, warehouseID, reason string, limit int) ([]*domain.StockAdjustment, error) {
+	var filter domain.StockAdjustmentFilter
+	var err error
+	if productID != "" {
+		if filter.ProductID, err = uuid.Parse(productID); err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+		}
+	}
+	if warehouseID != "" {
+		if filter.WarehouseID, err = uuid.Parse(warehouseID); err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+		}
+	}
+	filter.Reason = domain.StockAdjustmentReason(reason)
+
+	if limit <= 0 {
+		limit = defaultAdjustmentLimit
+	}
+	if limit > maxAdjustmentLimit {
+		limit = maxAdjustmentLimit
+	}
+
+	return s.warehouseRepo.FindAdjustments(filter, limit)
+}
+
+// RunReservationSweeper периодически освобождает просроченные резервы до отмены ctx
+func (s *InventoryService) RunReservationSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				released, changes, err := s.reservationRepo.ReleaseExpired(time.Now(), reservationSweepBatch)
+				if err != nil {
+					log.Printf("Failed to release expired reservations: %v", err)
+					break
+				}
+				s.stockChanged(changes)
+				if released > 0 {
+					log.Printf("Released %d expired reservations", released)
+				}
+				if released < reservationSweepBatch {
+					break
+				}
+			}
+		}
+	}
+}
+
+// ProcessOrderCreatedEvent списывает остатки по созданному заказу
+func (s *InventoryService) ProcessOrderCreatedEvent(data []byte) error {
+	return s.processOrderEvent(domain.TopicOrderCreated, data, -1)
+}
+
+// ProcessOrderCancelledEvent возвращает остатки по отмененному заказу
+func (s *InventoryService) ProcessOrderCancelledEvent(data []byte) error {
+	return s.processOrderEvent(domain.TopicOrderCancelled, data, 1)
+}
+
+// ProcessOrderRefundedEvent возвращает остатки по позициям возврата
+func (s *InventoryService) ProcessOrderRefundedEvent(data []byte) error {
+	return s.processOrderEvent(domain.TopicOrderRefunded, data, 1)
+}
+
+func (s *InventoryService) processOrderEvent(topic string, data []byte, sign int) error {
+	order, err := parseOrderEvent(topic, data)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := orderComponents(s.bundleRepo, topic, order)
+	if err != nil {
+		return err
+	}
+	event := domain.NewStockEvent(topic, order, sign)
+	event.Deltas = domain.OrderComponentDeltas(snapshot, refundedItems(topic, order), sign)
+	if topic == domain.TopicOrderCreated {
+		event.ReservationReferenceID = order.ID.String()
+	}
+
+	changes, err := s.inventoryRepo.ApplyStockEvent(event)
+	if errors.Is(err, domain.ErrEventAlreadyProcessed) {
+		log.Printf("Skipping already processed event %s", event.EventID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.stockChanged(changes)
+	return nil
+}
+
+// expandBundles заменяет наборы в движении остатков их компонентами
+func (s *InventoryService) expandBundles(deltas []domain.StockDelta) ([]domain.StockDelta, error) {
+	if len(deltas) == 0 {
+		return deltas, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(deltas))
+	for _, delta := range deltas {
+		ids = append(ids, delta.ProductID)
+	}
+	components, err := s.bundleRepo.FindComponents(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.ExpandBundles(deltas, components), nil
+}
+
+// orderComponents возвращает снимок состава заказа; orders.created сохраняет его, если снимка еще нет.
+// У заказов, созданных до появления снимков, остается раскладка по текущему составу наборов.
+func orderComponents(bundleRepo repository.BundleRepository, topic string, order *domain.OrderEvent) ([]domain.OrderComponent, error) {
+	snapshot, err := bundleRepo.FindOrderComponents(order.ID)
+	if err != nil || len(snapshot) > 0 {
+		return snapshot, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(order.Items))
+	for _, item := range order.Items {
+		ids = append(ids, item.ProductID)
+	}
+	components, err := bundleRepo.FindComponents(ids)
+	if err != nil {
+		return nil, err
+	}
+	snapshot = domain.NewOrderComponents(order, components)
+	// Возврат несет только возвращенные позиции, поэтому снимок всего заказа из него не построить
+	if topic != domain.TopicOrderCreated {
+		return snapshot, nil
+	}
+
+	return bundleRepo.SaveOrderComponents(snapshot)
+}
+
+// refundedItems возвращает позиции, по которым возвращаются остатки: у возврата — его позиции,
+// у остальных событий nil, то есть весь заказ
+func refundedItems(topic string, order *domain.OrderEvent) []domain.OrderEventItem {
+	if topic != domain.TopicOrderRefunded {
+		return nil
+	}
+	if order.Items == nil {
+		return []domain.OrderEventItem{}
+	}
+
+	return order.Items
+}
+
+// parseOrderEvent разбирает payload событий orders.*
+func parseOrderEvent(topic string, data []byte) (*domain.OrderEvent, error) {
+	var order domain.OrderEvent
+	if err := json.Unmarshal(data, &order); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal %s event: %v", kafka.ErrMalformedMessage, topic, err)
+	}
+	if order.ID == uuid.Nil {
+		return nil, fmt.Errorf("%w: %s event has no order ID", kafka.ErrMalformedMessage, topic)
+	}
+
+	return &order, nil
+}
+
+// stockChanged публикует product.stock_changed и события о пересечении порогов остатка
+func (s *InventoryService) stockChanged(changes []domain.StockChange) {
+	s.events.StockChanged(changes)
+
+	for _, change := range changes {
+		var topic string
+		switch {
+		case change.CrossedOutOfStock():
+			topic = domain.TopicInventoryOutOfStock
+		case change.CrossedLowStock(s.lowStockThreshold):
+			topic = domain.TopicInventoryLow
+		default:
+			continue
+		}
+
+		s.events.InventoryAlert(topic, &domain.InventoryAlertEvent{
+			ProductID:     change.ProductID,
+			Stock:         change.After,
+			PreviousStock: change.Before,
+			Threshold:     s.lowStockThreshold,
+			OccurredAt:    time.Now(),
+		})
+	}
+}
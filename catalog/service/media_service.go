@@ -0,0 +1,230 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/repository"
+	"github.com/yangirxd/store-app/catalog/storage"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"net/http"
+)
+
+const (
+	thumbnailSize = 320
+	// maxMediaPixels защищает от маленьких файлов, которые распаковываются в огромные изображения
+	maxMediaPixels = 50_000_000
+)
+
+// mediaExtensions — поддерживаемые типы изображений; тип определяется по содержимому, а не по имени файла
+var mediaExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+type MediaService struct {
+	mediaRepo   repository.MediaRepository
+	productRepo repository.ProductRepository
+	sellerRepo  repository.SellerRepository
+	blobStore   storage.BlobStore
+	events      *EventPublisher
+}
+
+func NewMediaService(mediaRepo repository.MediaRepository, productRepo repository.ProductRepository, sellerRepo repository.SellerRepository, blobStore storage.BlobStore, events *EventPublisher) *MediaService {
+	return &MediaService{
+		mediaRepo:   mediaRepo,
+		productRepo: productRepo,
+		sellerRepo:  sellerRepo,
+		blobStore:   blobStore,
+		events:      events,
+	}
+}
+
+// UploadMedia сохраняет изображение товара и его уменьшенную копию
+func (s *MediaService) UploadMedia(productID string, actor domain.Actor, r io.Reader) (*domain.ProductMedia, error) {
+	uid, err := uuid.Parse(productID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+	if err := s.authorize(uid, actor); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := http.DetectContentType(data)
+	extension, ok := mediaExtensions[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnsupportedMedia, contentType)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrUnsupportedMedia, err)
+	}
+	if config.Width == 0 || config.Height == 0 || config.Width*config.Height > maxMediaPixels {
+		return nil, fmt.Errorf("%w: image is %dx%d pixels", domain.ErrUnsupportedMedia, config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrUnsupportedMedia, err)
+	}
+	var thumbnail bytes.Buffer
+	if err := jpeg.Encode(&thumbnail, makeThumbnail(img, thumbnailSize), &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	media := domain.NewProductMedia(uid, extension, contentType, int64(len(data)), bounds.Dx(), bounds.Dy())
+	if err := s.blobStore.Put(media.Key, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	if err := s.blobStore.Put(media.ThumbnailKey, &thumbnail); err != nil {
+		s.deleteBlobs(media)
+		return nil, err
+	}
+	if err := s.mediaRepo.Create(media); err != nil {
+		s.deleteBlobs(media)
+		return nil, err
+	}
+	s.events.ProductChanged(uid, "media")
+
+	return media, nil
+}
+
+func (s *MediaService) GetMedia(productID string) ([]*domain.ProductMedia, error) {
+	uid, err := uuid.Parse(productID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+
+	return s.mediaRepo.FindByProductID(uid)
+}
+
+func (s *MediaService) DeleteMedia(productID, mediaID string, actor domain.Actor) error {
+	pid, err := uuid.Parse(productID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+	mid, err := uuid.Parse(mediaID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+	if err := s.authorize(pid, actor); err != nil {
+		return err
+	}
+
+	media, err := s.mediaRepo.FindByID(pid, mid)
+	if err != nil {
+		return err
+	}
+	if err := s.mediaRepo.Delete(media); err != nil {
+		return err
+	}
+	s.deleteBlobs(media)
+	s.events.ProductChanged(pid, "media")
+
+	return nil
+}
+
+// ReorderMedia задает порядок изображений товара; первое становится главным
+func (s *MediaService) ReorderMedia(productID string, actor domain.Actor, mediaIDs []uuid.UUID) ([]*domain.ProductMedia, error) {
+	uid, err := uuid.Parse(productID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+	if err := s.authorize(uid, actor); err != nil {
+		return nil, err
+	}
+
+	if err := s.mediaRepo.Reorder(uid, mediaIDs); err != nil {
+		return nil, err
+	}
+	s.events.ProductChanged(uid, "media")
+
+	return s.mediaRepo.FindByProductID(uid)
+}
+
+// authorize проверяет, что товар существует и actor может менять его изображения
+func (s *MediaService) authorize(productID uuid.UUID, actor domain.Actor) error {
+	product, err := s.productRepo.FindByID(productID)
+	if err != nil {
+		return err
+	}
+
+	return authorizeEdit(s.sellerRepo, product, actor)
+}
+
+// OpenBlob открывает файл изображения для раздачи
+func (s *MediaService) OpenBlob(key string) (*storage.Blob, error) {
+	return s.blobStore.Open(key)
+}
+
+// deleteBlobs удаляет файлы изображения. Ошибка только логируется: запись в базе уже удалена
+// или не создана, а оставшийся файл никому не виден.
+func (s *MediaService) deleteBlobs(media *domain.ProductMedia) {
+	for _, key := range []string{media.Key, media.ThumbnailKey} {
+		if err := s.blobStore.Delete(key); err != nil {
+			log.Printf("Failed to delete blob %s: %v", key, err)
+		}
+	}
+}
+
+// makeThumbnail уменьшает изображение так, чтобы оно вписалось в квадрат size×size.
+// Каждый пиксель результата — среднее по соответствующей области исходника;
+// прозрачные области заливаются белым, так как миниатюра сохраняется в JPEG.
+func makeThumbnail(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		size = max(width, height)
+	}
+
+	dstWidth, dstHeight := size, size
+	if width > height {
+		dstHeight = max(1, height*size/width)
+	} else {
+		dstWidth = max(1, width*size/height)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		y0 := bounds.Min.Y + y*height/dstHeight
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*height/dstHeight)
+		for x := 0; x < dstWidth; x++ {
+			x0 := bounds.Min.X + x*width/dstWidth
+			x1 := max(x0+1, bounds.Min.X+(x+1)*width/dstWidth)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			// Цвета в RGBA() уже умножены на альфу, поэтому белый фон добавляется как (1 - альфа)
+			background := 0xffff - a/n
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r/n + background),
+				G: uint16(g/n + background),
+				B: uint16(b/n + background),
+				A: 0xffff,
+			})
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/repository"
+	"log"
+	"time"
+)
+
+const (
+	defaultPriceHistoryLimit = 100
+	priceScheduleBatch       = 100
+)
+
+type PriceHistoryService struct {
+	productRepo  repository.ProductRepository
+	historyRepo  repository.PriceHistoryRepository
+	scheduleRepo repository.PriceScheduleRepository
+	sellerRepo   repository.SellerRepository
+	audit        *AuditService
+	events       *EventPublisher
+}
+
+func NewPriceHistoryService(productRepo repository.ProductRepository, historyRepo repository.PriceHistoryRepository, scheduleRepo repository.PriceScheduleRepository, sellerRepo repository.SellerRepository, audit *AuditService, events *EventPublisher) *PriceHistoryService {
+	return &PriceHistoryService{
+		productRepo:  productRepo,
+		historyRepo:  historyRepo,
+		scheduleRepo: scheduleRepo,
+		sellerRepo:   sellerRepo,
+		audit:        audit,
+		events:       events,
+	}
+}
+
+// RecordPriceChange добавляет запись в историю, если базовая цена товара изменилась.
+// Ошибка только логируется: изменение товара уже сохранено.
+func (s *PriceHistoryService) RecordPriceChange(before, after *domain.Product, reason domain.PriceChangeReason) {
+	s.recordPriceChange(before, after, reason, nil)
+}
+
+func (s *PriceHistoryService) recordPriceChange(before, after *domain.Product, reason domain.PriceChangeReason, scheduleID *uuid.UUID) {
+	if before.Price == after.Price {
+		return
+	}
+
+	entry := domain.NewPriceHistory(after.ID, before.Price, after.Price, reason)
+	entry.ScheduleID = scheduleID
+	if err := s.historyRepo.Append(entry); err != nil {
+		log.Printf("Failed to record price history for product %s: %v", after.ID, err)
+	}
+}
+
+// GetPriceHistory возвращает последние изменения цены публичного товара, новые первыми
+func (s *PriceHistoryService) GetPriceHistory(productID string, limit int) ([]*domain.PriceHistory, error) {
+	uid, err := uuid.Parse(productID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+	if limit <= 0 {
+		limit = defaultPriceHistoryLimit
+	}
+
+	product, err := s.productRepo.FindByID(uid)
+	if err != nil {
+		return nil, err
+	}
+	if !product.IsPublic() {
+		return nil, domain.ErrProductNotFound
+	}
+
+	return s.historyRepo.FindByProductID(uid, limit)
+}
+
+// SchedulePrice планирует цену товара на период. Периоды открытых расписаний одного товара не должны пересекаться.
+// Планировать цену может продавец товара или администратор.
+func (s *PriceHistoryService) SchedulePrice(productID string, price float64, startsAt time.Time, endsAt *time.Time, actor domain.Actor) (*domain.PriceSchedule, error) {
+	uid, err := uuid.Parse(productID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+
+	schedule, err := domain.NewPriceSchedule(uid, price, startsAt, endsAt)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := s.productRepo.FindByID(uid)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeEdit(s.sellerRepo, product, actor); err != nil {
+		return nil, err
+	}
+
+	open, err := s.scheduleRepo.FindOpenByProductID(uid)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range open {
+		if schedule.Overlaps(existing) {
+			return nil, domain.ErrScheduleOverlap
+		}
+	}
+
+	if err := s.scheduleRepo.Create(schedule); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// GetPriceSchedules возвращает расписания цен товара. Будущие цены — коммерческая тайна продавца,
+// поэтому видеть их может только продавец товара или администратор.
+func (s *PriceHistoryService) GetPriceSchedules(productID string, actor domain.Actor) ([]*domain.PriceSchedule, error) {
+	uid, err := uuid.Parse(productID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+
+	product, err := s.productRepo.FindByID(uid)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeEdit(s.sellerRepo, product, actor); err != nil {
+		return nil, err
+	}
+
+	return s.scheduleRepo.FindByProductID(uid)
+}
+
+// CancelPriceSchedule отменяет ожидающее расписание. У действующего расписания
+// конец переносится на текущий момент, и планировщик вернет прежнюю цену.
+func (s *PriceHistoryService) CancelPriceSchedule(id string, actor domain.Actor) (*domain.PriceSchedule, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+
+	schedule, err := s.scheduleRepo.FindByID(uid)
+	if err != nil {
+		return nil, err
+	}
+	product, err := s.productRepo.FindByID(schedule.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeEdit(s.sellerRepo, product, actor); err != nil {
+		return nil, err
+	}
+
+	from := schedule.Status
+	switch schedule.Status {
+	case domain.SchedulePending:
+		schedule.Status = domain.ScheduleCancelled
+	case domain.ScheduleActive:
+		now := time.Now()
+		schedule.EndsAt = &now
+	default:
+		return schedule, nil
+	}
+
+	ok, err := s.scheduleRepo.Transition(schedule, from)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// Планировщик успел изменить расписание — возвращаем актуальное состояние
+		return s.scheduleRepo.FindByID(uid)
+	}
+
+	return schedule, nil
+}
+
+// RunPriceScheduler периодически запускает и завершает расписания цен до отмены ctx
+func (s *PriceHistoryService) RunPriceScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.processDueSchedules(time.Now())
+		}
+	}
+}
+
+func (s *PriceHistoryService) processDueSchedules(now time.Time) {
+	schedules, err := s.scheduleRepo.FindDue(now, priceScheduleBatch)
+	if err != nil {
+		log.Printf("Failed to load due price schedules: %v", err)
+		return
+	}
+
+	for _, schedule := range schedules {
+		var err error
+		if schedule.Status == domain.SchedulePending {
+			err = s.activateSchedule(schedule, now)
+		} else {
+			err = s.completeSchedule(schedule)
+		}
+		if err != nil {
+			log.Printf("Failed to process price schedule %s: %v", schedule.ID, err)
+		}
+	}
+}
+
+// activateSchedule устанавливает запланированную цену и запоминает прежнюю
+func (s *PriceHistoryService) activateSchedule(schedule *domain.PriceSchedule, now time.Time) error {
+	product, err := s.productRepo.FindByID(schedule.ProductID)
+	if errors.Is(err, domain.ErrProductNotFound) || (err == nil && schedule.IsOver(now)) {
+		// Товар удален или окно расписания уже прошло — цену не меняем
+		schedule.Status = domain.ScheduleCompleted
+		_, err = s.scheduleRepo.Transition(schedule, domain.SchedulePending)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	previousPrice := product.Price
+	schedule.Status = domain.ScheduleActive
+	schedule.PreviousPrice = &previousPrice
+	ok, err := s.scheduleRepo.Transition(schedule, domain.SchedulePending)
+	if err != nil || !ok {
+		return err
+	}
+
+	before := *product
+	product.Price = schedule.Price
+	if err := s.productRepo.Update(product); err != nil {
+		// Возвращаем расписание в ожидание, чтобы повторить на следующем тике
+		schedule.Status = domain.SchedulePending
+		schedule.PreviousPrice = nil
+		if _, rollbackErr := s.scheduleRepo.Transition(schedule, domain.ScheduleActive); rollbackErr != nil {
+			log.Printf("Failed to reset price schedule %s: %v", schedule.ID, rollbackErr)
+		}
+		return err
+	}
+	s.recordPriceChange(&before, product, domain.PriceChangeScheduleStart, &schedule.ID)
+	s.audit.Record(domain.AuditUpdated, domain.SystemActor, &before, product)
+	s.events.ProductUpdated(&before, product)
+
+	return nil
+}
+
+// completeSchedule возвращает прежнюю цену. Если цену за время действия расписания
+// изменили вручную, она остается как есть.
+func (s *PriceHistoryService) completeSchedule(schedule *domain.PriceSchedule) error {
+	product, err := s.productRepo.FindByID(schedule.ProductID)
+	if err != nil && !errors.Is(err, domain.ErrProductNotFound) {
+		return err
+	}
+
+	schedule.Status = domain.ScheduleCompleted
+	ok, err := s.scheduleRepo.Transition(schedule, domain.ScheduleActive)
+	if err != nil || !ok || product == nil {
+		return err
+	}
+	if schedule.PreviousPrice == nil || product.Price != schedule.Price {
+		return nil
+	}
+
+	before := *product
+	product.Price = *schedule.PreviousPrice
+	if err := s.productRepo.Update(product); err != nil {
+		// Возвращаем расписание в действующее, чтобы повторить на следующем тике
+		schedule.Status = domain.ScheduleActive
+		if _, rollbackErr := s.scheduleRepo.Transition(schedule, domain.ScheduleCompleted); rollbackErr != nil {
+			log.Printf("Failed to reset price schedule %s: %v", schedule.ID, rollbackErr)
+		}
+		return err
+	}
+	s.recordPriceChange(&before, product, domain.PriceChangeScheduleEnd, &schedule.ID)
+	s.audit.Record(domain.AuditUpdated, domain.SystemActor, &before, product)
+	s.events.ProductUpdated(&before, product)
+
+	return nil
+}
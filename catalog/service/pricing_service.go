@@ -0,0 +1,249 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/repository"
+	"strings"
+)
+
+type PricingService struct {
+	priceListRepo    repository.PriceListRepository
+	taxRateRepo      repository.TaxRateRepository
+	productRepo      repository.ProductRepository
+	sellerRepo       repository.SellerRepository
+	rates            *domain.ExchangeRates
+	pricesIncludeTax bool // Включен ли налог в цены каталога и прайс-листов
+}
+
+func NewPricingService(priceListRepo repository.PriceListRepository, taxRateRepo repository.TaxRateRepository, productRepo repository.ProductRepository, sellerRepo repository.SellerRepository, rates *domain.ExchangeRates, pricesIncludeTax bool) *PricingService {
+	return &PricingService{
+		priceListRepo:    priceListRepo,
+		taxRateRepo:      taxRateRepo,
+		productRepo:      productRepo,
+		sellerRepo:       sellerRepo,
+		rates:            rates,
+		pricesIncludeTax: pricesIncludeTax,
+	}
+}
+
+func (s *PricingService) CreatePriceList(currency, market string, isDefault bool) (*domain.PriceList, error) {
+	priceList, err := domain.NewPriceList(currency, market, isDefault)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.priceListRepo.Create(priceList); err != nil {
+		return nil, err
+	}
+
+	return priceList, nil
+}
+
+func (s *PricingService) GetPriceLists() ([]*domain.PriceList, error) {
+	return s.priceListRepo.FindAll()
+}
+
+// SetProductPrice переопределяет цену товара в прайс-листе; менять ее может продавец товара или администратор
+func (s *PricingService) SetProductPrice(priceListID, productID string, price float64, actor domain.Actor) (*domain.PriceListEntry, error) {
+	listID, err := uuid.Parse(priceListID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+	pid, err := uuid.Parse(productID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+
+	if _, err := s.priceListRepo.FindByID(listID); err != nil {
+		return nil, err
+	}
+	if err := s.authorizeProduct(pid, actor); err != nil {
+		return nil, err
+	}
+
+	entry, err := domain.NewPriceListEntry(listID, pid, price)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.priceListRepo.UpsertEntry(entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (s *PricingService) DeleteProductPrice(priceListID, productID string, actor domain.Actor) error {
+	listID, err := uuid.Parse(priceListID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+	pid, err := uuid.Parse(productID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+
+	if _, err := s.priceListRepo.FindByID(listID); err != nil {
+		return err
+	}
+	if err := s.authorizeProduct(pid, actor); err != nil {
+		return err
+	}
+
+	return s.priceListRepo.DeleteEntry(listID, pid)
+}
+
+// authorizeProduct проверяет, что actor может менять цены товара productID
+func (s *PricingService) authorizeProduct(productID uuid.UUID, actor domain.Actor) error {
+	product, err := s.productRepo.FindByID(productID)
+	if err != nil {
+		return err
+	}
+
+	return authorizeEdit(s.sellerRepo, product, actor)
+}
+
+// ApplyPrices пересчитывает Price товаров в запрошенной валюте.
+// Сначала ищется переопределение в прайс-листе рынка (или в прайс-листе
+// валюты по умолчанию), иначе базовая цена конвертируется по курсу.
+func (s *PricingService) ApplyPrices(products []*domain.Product, currency, market string) error {
+	currency = domain.NormalizeCurrency(currency)
+	market = strings.ToUpper(strings.TrimSpace(market))
+	if currency == "" {
+		if market == "" {
+			for _, product := range products {
+				product.Currency = s.rates.Base
+			}
+			return nil
+		}
+		currency = s.rates.Base
+	}
+
+	overrides, err := s.findOverrides(products, currency, market)
+	if err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		if price, ok := overrides[product.ID]; ok {
+			product.Price = price
+		} else {
+			converted, err := s.rates.Convert(product.Price, currency)
+			if err != nil {
+				return err
+			}
+			product.Price = converted
+		}
+		product.Currency = currency
+	}
+
+	return nil
+}
+
+// ApplyTaxes заполняет цены с налогом и без него для страны и региона доставки.
+// Без страны товары отдаются как есть. Вызывается после ApplyPrices, налог считается от итоговой цены.
+// Товар, для класса которого в стране нет ставки, остается без цен с налогом и без него:
+// одна ненастроенная ставка не должна ломать весь список.
+func (s *PricingService) ApplyTaxes(products []*domain.Product, country, region string) error {
+	if strings.TrimSpace(country) == "" {
+		return nil
+	}
+	destination, err := domain.ParseTaxDestination(country, region)
+	if err != nil {
+		return err
+	}
+
+	rates, err := s.taxRateRepo.FindByCountry(destination.Country)
+	if err != nil {
+		return err
+	}
+	for _, product := range products {
+		rate, err := domain.SelectTaxRate(rates, destination, product.TaxClass)
+		if errors.Is(err, domain.ErrTaxRateNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		product.ApplyTax(rate, s.pricesIncludeTax)
+	}
+
+	return nil
+}
+
+// SetTaxRate создает ставку или заменяет существующую для той же страны, региона и класса
+func (s *PricingService) SetTaxRate(country, region, taxClass string, rate float64) (*domain.TaxRate, error) {
+	taxRate, err := domain.NewTaxRate(country, region, taxClass, rate)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.taxRateRepo.Save(taxRate); err != nil {
+		return nil, err
+	}
+
+	return taxRate, nil
+}
+
+func (s *PricingService) GetTaxRates() ([]*domain.TaxRate, error) {
+	return s.taxRateRepo.FindAll()
+}
+
+func (s *PricingService) DeleteTaxRate(id string) error {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+
+	return s.taxRateRepo.Delete(uid)
+}
+
+func (s *PricingService) findOverrides(products []*domain.Product, currency, market string) (map[uuid.UUID]float64, error) {
+	overrides := make(map[uuid.UUID]float64)
+	if len(products) == 0 {
+		return overrides, nil
+	}
+
+	priceLists, err := s.priceListRepo.FindByCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	priceList := selectPriceList(priceLists, market)
+	if priceList == nil {
+		return overrides, nil
+	}
+
+	productIDs := make([]uuid.UUID, 0, len(products))
+	for _, product := range products {
+		productIDs = append(productIDs, product.ID)
+	}
+
+	entries, err := s.priceListRepo.FindEntries(priceList.ID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		overrides[entry.ProductID] = entry.Price
+	}
+
+	return overrides, nil
+}
+
+// selectPriceList выбирает прайс-лист рынка, а при его отсутствии — прайс-лист по умолчанию
+func selectPriceList(priceLists []*domain.PriceList, market string) *domain.PriceList {
+	var fallback *domain.PriceList
+	for _, priceList := range priceLists {
+		if market != "" && priceList.Market == market {
+			return priceList
+		}
+		if priceList.IsDefault || (fallback == nil && priceList.Market == "") {
+			fallback = priceList
+		}
+	}
+
+	return fallback
+}
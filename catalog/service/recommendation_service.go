@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/repository"
+	"log"
+)
+
+const (
+	defaultRelatedLimit = 10
+	maxRelatedLimit     = 50
+)
+
+type RecommendationService struct {
+	recommendationRepo repository.RecommendationRepository
+	catalog            *CatalogService
+	categoryAttribute  string // Код характеристики, по которой товары без совместных покупок подбираются из той же категории
+}
+
+func NewRecommendationService(recommendationRepo repository.RecommendationRepository, catalog *CatalogService, categoryAttribute string) *RecommendationService {
+	return &RecommendationService{
+		recommendationRepo: recommendationRepo,
+		catalog:            catalog,
+		categoryAttribute:  categoryAttribute,
+	}
+}
+
+// GetRelatedProducts возвращает товары, которые чаще всего покупают вместе с товаром.
+// Если таких меньше limit, список дополняется товарами той же категории.
+func (s *RecommendationService) GetRelatedProducts(id string, limit int) ([]*domain.Product, error) {
+	product, err := s.catalog.GetProductByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultRelatedLimit
+	}
+	if limit > maxRelatedLimit {
+		limit = maxRelatedLimit
+	}
+
+	ids, err := s.recommendationRepo.FindCoPurchased(product.ID, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) < limit && s.categoryAttribute != "" {
+		sameCategory, err := s.recommendationRepo.FindSameAttribute(product.ID, s.categoryAttribute, ids, limit-len(ids))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, sameCategory...)
+	}
+	if len(ids) == 0 {
+		return []*domain.Product{}, nil
+	}
+
+	return s.catalog.findPublicByIDs(ids)
+}
+
+// ProcessOrderCreatedEvent учитывает товары заказа как купленные вместе
+func (s *RecommendationService) ProcessOrderCreatedEvent(data []byte) error {
+	order, err := parseOrderEvent(domain.TopicOrderCreated, data)
+	if err != nil {
+		return err
+	}
+
+	err = s.recommendationRepo.RecordOrder(order.ID, domain.CoPurchases(order.Items))
+	if errors.Is(err, domain.ErrEventAlreadyProcessed) {
+		log.Printf("Skipping order %s already counted in co-purchases", order.ID)
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"encoding/csv"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/repository"
+	"io"
+	"strconv"
+	"time"
+)
+
+var replenishmentCSVHeader = []string{"sku", "name", "stock", "reorder_threshold", "target_stock", "sold", "daily_sales", "suggested_quantity"}
+
+type ReplenishmentService struct {
+	replenishmentRepo repository.ReplenishmentRepository
+	bundleRepo        repository.BundleRepository
+}
+
+func NewReplenishmentService(replenishmentRepo repository.ReplenishmentRepository, bundleRepo repository.BundleRepository) *ReplenishmentService {
+	return &ReplenishmentService{
+		replenishmentRepo: replenishmentRepo,
+		bundleRepo:        bundleRepo,
+	}
+}
+
+// GetReport возвращает товары, остаток которых опустился до порога дозаказа, с предложенным
+// количеством дозаказа по продажам за последние days дней (0 — период по умолчанию)
+func (s *ReplenishmentService) GetReport(days int) ([]domain.ReplenishmentLine, error) {
+	since, days, err := domain.SalesWindowStart(time.Now(), days)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.replenishmentRepo.FindBelowThreshold()
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]domain.ReplenishmentLine, 0, len(products))
+	if len(products) == 0 {
+		return lines, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product.ID)
+	}
+	sold, err := s.replenishmentRepo.CountSales(ids, since)
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range products {
+		lines = append(lines, domain.NewReplenishmentLine(product, sold[product.ID], days))
+	}
+
+	return lines, nil
+}
+
+// ExportReport пишет строки отчета в w в формате CSV
+func (s *ReplenishmentService) ExportReport(lines []domain.ReplenishmentLine, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(replenishmentCSVHeader); err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if err := writer.Write([]string{
+			line.SKU,
+			line.Name,
+			strconv.Itoa(line.Stock),
+			strconv.Itoa(line.ReorderThreshold),
+			strconv.Itoa(line.TargetStock),
+			strconv.Itoa(line.Sold),
+			strconv.FormatFloat(line.DailySales, 'f', -1, 64),
+			strconv.Itoa(line.SuggestedQuantity),
+		}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// ProcessOrderCreatedEvent учитывает позиции заказа как продажи; наборы учитываются компонентами
+func (s *ReplenishmentService) ProcessOrderCreatedEvent(data []byte) error {
+	order, err := parseOrderEvent(domain.TopicOrderCreated, data)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := orderComponents(s.bundleRepo, domain.TopicOrderCreated, order)
+	if err != nil {
+		return err
+	}
+	deltas := domain.OrderComponentDeltas(snapshot, nil, 1)
+
+	return s.replenishmentRepo.RecordSales(domain.NewProductSales(order.ID, deltas, time.Now()))
+}
+
+// ProcessOrderCancelledEvent забывает продажи отмененного заказа
+func (s *ReplenishmentService) ProcessOrderCancelledEvent(data []byte) error {
+	order, err := parseOrderEvent(domain.TopicOrderCancelled, data)
+	if err != nil {
+		return err
+	}
+
+	return s.replenishmentRepo.RemoveSales(order.ID, nil)
+}
+
+// ProcessOrderRefundedEvent забывает продажи возвращенных позиций заказа; компоненты наборов
+// берутся из снимка состава на момент заказа
+func (s *ReplenishmentService) ProcessOrderRefundedEvent(data []byte) error {
+	order, err := parseOrderEvent(domain.TopicOrderRefunded, data)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := orderComponents(s.bundleRepo, domain.TopicOrderRefunded, order)
+	if err != nil {
+		return err
+	}
+	deltas := domain.OrderComponentDeltas(snapshot, refundedItems(domain.TopicOrderRefunded, order), 1)
+	if len(deltas) == 0 {
+		return nil
+	}
+	productIDs := make([]uuid.UUID, 0, len(deltas))
+	for _, delta := range deltas {
+		productIDs = append(productIDs, delta.ProductID)
+	}
+
+	return s.replenishmentRepo.RemoveSales(order.ID, productIDs)
+}
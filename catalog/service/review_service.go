@@ -0,0 +1,191 @@
+package service
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/repository"
+	"time"
+)
+
+const (
+	defaultReviewPageSize = 20
+	maxReviewPageSize     = 100
+)
+
+type ReviewService struct {
+	reviewRepo   repository.ReviewRepository
+	purchaseRepo repository.PurchaseRepository
+	productRepo  repository.ProductRepository
+	events       *EventPublisher
+}
+
+func NewReviewService(reviewRepo repository.ReviewRepository, purchaseRepo repository.PurchaseRepository, productRepo repository.ProductRepository, events *EventPublisher) *ReviewService {
+	return &ReviewService{
+		reviewRepo:   reviewRepo,
+		purchaseRepo: purchaseRepo,
+		productRepo:  productRepo,
+		events:       events,
+	}
+}
+
+// CreateReview добавляет отзыв, если товар был доставлен пользователю
+func (s *ReviewService) CreateReview(productID, userEmail string, rating int, title, comment string) (*domain.Review, error) {
+	uid, err := uuid.Parse(productID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+
+	product, err := s.productRepo.FindByID(uid)
+	if err != nil {
+		return nil, err
+	}
+	if !product.IsPublic() {
+		return nil, domain.ErrProductNotFound
+	}
+
+	delivered, err := s.purchaseRepo.HasDelivered(domain.NormalizeEmail(userEmail), uid)
+	if err != nil {
+		return nil, err
+	}
+	if !delivered {
+		return nil, domain.ErrNotPurchased
+	}
+
+	review, err := domain.NewReview(uid, userEmail, rating, title, comment)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.reviewRepo.Create(review); err != nil {
+		return nil, err
+	}
+	s.events.ProductChanged(review.ProductID, "rating")
+
+	return review, nil
+}
+
+// UpdateReview изменяет собственный отзыв пользователя
+func (s *ReviewService) UpdateReview(reviewID, userEmail string, rating int, title, comment string) (*domain.Review, error) {
+	review, err := s.findOwnReview(reviewID, userEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := review.Edit(rating, title, comment); err != nil {
+		return nil, err
+	}
+	if err := s.reviewRepo.Update(review); err != nil {
+		return nil, err
+	}
+	s.events.ProductChanged(review.ProductID, "rating")
+
+	return review, nil
+}
+
+// DeleteReview удаляет собственный отзыв пользователя
+func (s *ReviewService) DeleteReview(reviewID, userEmail string) error {
+	review, err := s.findOwnReview(reviewID, userEmail)
+	if err != nil {
+		return err
+	}
+
+	if err := s.reviewRepo.Delete(review); err != nil {
+		return err
+	}
+	s.events.ProductChanged(review.ProductID, "rating")
+
+	return nil
+}
+
+func (s *ReviewService) findOwnReview(reviewID, userEmail string) (*domain.Review, error) {
+	uid, err := uuid.Parse(reviewID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+
+	review, err := s.reviewRepo.FindByID(uid)
+	if err != nil {
+		return nil, err
+	}
+	if domain.NormalizeEmail(review.UserEmail) != domain.NormalizeEmail(userEmail) {
+		return nil, domain.ErrReviewForbidden
+	}
+
+	return review, nil
+}
+
+// ReviewPage — страница отзывов товара
+type ReviewPage struct {
+	Reviews  []*domain.Review
+	Total    int64
+	Page     int
+	PageSize int
+}
+
+// GetReviews возвращает страницу отзывов товара; страницы нумеруются с 1
+func (s *ReviewService) GetReviews(productID string, page, pageSize int) (*ReviewPage, error) {
+	uid, err := uuid.Parse(productID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = defaultReviewPageSize
+	}
+	if pageSize > maxReviewPageSize {
+		pageSize = maxReviewPageSize
+	}
+
+	reviews, total, err := s.reviewRepo.FindByProductID(uid, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReviewPage{Reviews: reviews, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// ProcessOrderCreatedEvent запоминает позиции заказа покупателя
+func (s *ReviewService) ProcessOrderCreatedEvent(data []byte) error {
+	order, err := parseOrderEvent(domain.TopicOrderCreated, data)
+	if err != nil {
+		return err
+	}
+
+	return s.purchaseRepo.RecordOrder(order)
+}
+
+// ProcessOrderDeliveredEvent открывает покупателю возможность оставить отзыв
+func (s *ReviewService) ProcessOrderDeliveredEvent(data []byte) error {
+	order, err := parseOrderEvent(domain.TopicOrderDelivered, data)
+	if err != nil {
+		return err
+	}
+
+	return s.purchaseRepo.MarkDelivered(order, time.Now())
+}
+
+// ProcessOrderCancelledEvent забывает позиции отмененного заказа
+func (s *ReviewService) ProcessOrderCancelledEvent(data []byte) error {
+	order, err := parseOrderEvent(domain.TopicOrderCancelled, data)
+	if err != nil {
+		return err
+	}
+
+	return s.purchaseRepo.Remove(order.ID, nil)
+}
+
+// ProcessOrderRefundedEvent забывает возвращенные позиции заказа
+func (s *ReviewService) ProcessOrderRefundedEvent(data []byte) error {
+	order, err := parseOrderEvent(domain.TopicOrderRefunded, data)
+	if err != nil {
+		return err
+	}
+
+	productIDs := make([]uuid.UUID, 0, len(order.Items))
+	for _, item := range order.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	return s.purchaseRepo.Remove(order.ID, productIDs)
+}
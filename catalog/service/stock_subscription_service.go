@@ -0,0 +1,107 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/kafka"
+	"github.com/yangirxd/store-app/catalog/repository"
+)
+
+type StockSubscriptionService struct {
+	subscriptionRepo repository.StockSubscriptionRepository
+	bundleRepo       repository.BundleRepository
+	catalog          *CatalogService
+	events           *EventPublisher
+}
+
+func NewStockSubscriptionService(subscriptionRepo repository.StockSubscriptionRepository, bundleRepo repository.BundleRepository, catalog *CatalogService, events *EventPublisher) *StockSubscriptionService {
+	return &StockSubscriptionService{
+		subscriptionRepo: subscriptionRepo,
+		bundleRepo:       bundleRepo,
+		catalog:          catalog,
+		events:           events,
+	}
+}
+
+// Subscribe подписывает пользователя на поступление товара, которого нет в наличии
+func (s *StockSubscriptionService) Subscribe(productID, userEmail string) (*domain.StockSubscription, error) {
+	product, err := s.catalog.GetProductByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.Stock > 0 {
+		return nil, domain.ErrProductInStock
+	}
+
+	subscription := domain.NewStockSubscription(product.ID, userEmail)
+	if err := s.subscriptionRepo.Create(subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (s *StockSubscriptionService) Unsubscribe(productID, userEmail string) error {
+	uid, err := uuid.Parse(productID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+
+	return s.subscriptionRepo.Delete(uid, userEmail)
+}
+
+// ProcessStockChangedEvent уведомляет подписчиков, когда остаток становится положительным.
+// product.stock_changed публикуется при любом изменении остатка: правке товара,
+// заказах, резервах и корректировках, поэтому других точек входа не нужно.
+func (s *StockSubscriptionService) ProcessStockChangedEvent(data []byte) error {
+	var event domain.ProductEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return fmt.Errorf("%w: failed to unmarshal %s event: %v", kafka.ErrMalformedMessage, domain.TopicProductStockChanged, err)
+	}
+	if event.OldStock == nil || event.NewStock == nil || *event.NewStock <= *event.OldStock {
+		return nil
+	}
+
+	if *event.OldStock <= 0 {
+		if err := s.notify(event.ProductID, *event.NewStock); err != nil {
+			return err
+		}
+	}
+
+	// Набор может стать доступным от прихода любого компонента, даже если тот уже был в наличии
+	bundleIDs, err := s.subscriptionRepo.FindSubscribedBundles(event.ProductID)
+	if err != nil || len(bundleIDs) == 0 {
+		return err
+	}
+	availability, err := s.bundleRepo.FindAvailability(bundleIDs)
+	if err != nil {
+		return err
+	}
+	for _, bundleID := range bundleIDs {
+		if availability[bundleID] > 0 {
+			if err := s.notify(bundleID, availability[bundleID]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// notify публикует product.back_in_stock со списком подписчиков и только после этого удаляет их подписки.
+// Если публикация не удалась, подписки остаются, а ошибка заставляет consumer повторить событие;
+// сбой после публикации может привести к повторному уведомлению, но не к потерянному.
+func (s *StockSubscriptionService) notify(productID uuid.UUID, stock int) error {
+	subscribers, err := s.subscriptionRepo.FindSubscribers(productID)
+	if err != nil || len(subscribers) == 0 {
+		return err
+	}
+
+	if err := s.events.BackInStock(domain.NewBackInStockEvent(productID, stock, subscribers)); err != nil {
+		return err
+	}
+
+	return s.subscriptionRepo.DeleteSubscribers(productID, subscribers)
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/yangirxd/store-app/catalog/domain"
+	"github.com/yangirxd/store-app/catalog/repository"
+)
+
+type TranslationService struct {
+	translationRepo repository.TranslationRepository
+	productRepo     repository.ProductRepository
+	events          *EventPublisher
+	defaultLocale   string // Язык, на котором заполнены Name и Description самого товара
+}
+
+func NewTranslationService(translationRepo repository.TranslationRepository, productRepo repository.ProductRepository, events *EventPublisher, defaultLocale string) *TranslationService {
+	return &TranslationService{
+		translationRepo: translationRepo,
+		productRepo:     productRepo,
+		events:          events,
+		defaultLocale:   defaultLocale,
+	}
+}
+
+// SetTranslation создает или заменяет перевод товара на locale
+func (s *TranslationService) SetTranslation(productID, locale, name, description string) (*domain.ProductTranslation, error) {
+	uid, err := uuid.Parse(productID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+
+	translation, err := domain.NewProductTranslation(uid, locale, name, description)
+	if err != nil {
+		return nil, err
+	}
+	if translation.Locale == s.defaultLocale {
+		return nil, fmt.Errorf("%w: %s is the default locale, edit the product itself", domain.ErrInvalidTranslation, s.defaultLocale)
+	}
+
+	if err := s.translationRepo.Save(translation); err != nil {
+		return nil, err
+	}
+	s.events.ProductChanged(uid, "translations")
+
+	return translation, nil
+}
+
+func (s *TranslationService) DeleteTranslation(productID, locale string) error {
+	uid, err := uuid.Parse(productID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+	locale, err = domain.ParseLocale(locale)
+	if err != nil {
+		return err
+	}
+
+	if err := s.translationRepo.Delete(uid, locale); err != nil {
+		return err
+	}
+	s.events.ProductChanged(uid, "translations")
+
+	return nil
+}
+
+func (s *TranslationService) GetTranslations(productID string) ([]*domain.ProductTranslation, error) {
+	uid, err := uuid.Parse(productID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidID, err)
+	}
+	if _, err := s.productRepo.FindByID(uid); err != nil {
+		return nil, err
+	}
+
+	return s.translationRepo.FindByProductID(uid)
+}
+
+// GetMissingTranslations возвращает товары, у которых нет перевода на locale
+func (s *TranslationService) GetMissingTranslations(locale string) ([]*domain.Product, error) {
+	locale, err := domain.ParseLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	if locale == s.defaultLocale {
+		return nil, fmt.Errorf("%w: %s is the default locale", domain.ErrInvalidTranslation, s.defaultLocale)
+	}
+
+	return s.translationRepo.FindProductsMissing(locale)
+}
+
+// Localize переводит товары по языкам в порядке предпочтения клиента.
+// Возвращает язык, если все товары отданы на одном языке, иначе пустую строку.
+func (s *TranslationService) Localize(products []*domain.Product, preferred []string) (string, error) {
+	chain := domain.LocaleFallbacks(preferred, s.defaultLocale)
+	translations := make(map[uuid.UUID]map[string]*domain.ProductTranslation)
+	if len(chain) > 1 && len(products) > 0 {
+		ids := make([]uuid.UUID, 0, len(products))
+		for _, product := range products {
+			ids = append(ids, product.ID)
+		}
+		found, err := s.translationRepo.FindByProductIDs(ids, chain)
+		if err != nil {
+			return "", err
+		}
+		for _, translation := range found {
+			if translations[translation.ProductID] == nil {
+				translations[translation.ProductID] = make(map[string]*domain.ProductTranslation)
+			}
+			translations[translation.ProductID][translation.Locale] = translation
+		}
+	}
+
+	locale := s.defaultLocale
+	for i, product := range products {
+		product.Localize(chain, s.defaultLocale, translations[product.ID])
+		if i == 0 {
+			locale = product.Locale
+		} else if product.Locale != locale {
+			locale = ""
+		}
+	}
+
+	return locale, nil
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+// Blob — открытый для чтения объект хранилища
+type Blob struct {
+	io.ReadSeekCloser
+	Size    int64
+	ModTime time.Time
+}
+
+// BlobStore хранит бинарные объекты (изображения товаров) по ключу вида products/<id>/<file>
+type BlobStore interface {
+	Put(key string, r io.Reader) error
+	Open(key string) (*Blob, error)
+	Delete(key string) error
+}
+
+// LocalBlobStore хранит объекты в файлах внутри корневого каталога
+type LocalBlobStore struct {
+	root string
+}
+
+func NewLocalBlobStore(root string) (*LocalBlobStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &LocalBlobStore{root: root}, nil
+}
+
+// Put записывает объект во временный файл и переименовывает его, чтобы читатели не видели недописанный файл
+func (s *LocalBlobStore) Put(key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+func (s *LocalBlobStore) Open(key string) (*Blob, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrBlobNotFound
+		}
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, ErrBlobNotFound
+	}
+
+	return &Blob{ReadSeekCloser: file, Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+func (s *LocalBlobStore) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// path переводит ключ в путь к файлу, не позволяя выйти за пределы корневого каталога
+func (s *LocalBlobStore) path(key string) (string, error) {
+	if !filepath.IsLocal(key) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
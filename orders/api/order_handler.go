@@ -1,10 +1,12 @@
 package api
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/yangirxd/store-app/orders/api/dto"
 	_ "github.com/yangirxd/store-app/orders/docs"
+	"github.com/yangirxd/store-app/orders/domain"
 	"github.com/yangirxd/store-app/orders/service"
 	"net/http"
 )
@@ -117,3 +119,80 @@ func getOrdersHandler(orderService *service.OrderService) gin.HandlerFunc {
 		c.JSON(http.StatusOK, orders)
 	}
 }
+
+// @Summary Cancel order
+// @Description Cancel a newly created order of the authenticated user; catalog returns its stock (requires authentication)
+// @Tags orders
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param orderID path string true "Order ID"
+// @Success 200 {object} domain.Order
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Order not found"
+// @Failure 409 {string} string "Order cannot be cancelled"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/orders/{orderID}/cancel [post]
+func cancelOrderHandler(orderService *service.OrderService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userEmail := c.GetString("userEmail")
+		if userEmail == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user email not found in token"})
+			return
+		}
+		orderID, err := uuid.Parse(c.Param("orderID"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+			return
+		}
+		order, err := orderService.CancelOrder(orderID, userEmail)
+		if err != nil {
+			c.JSON(orderErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, order)
+	}
+}
+
+// @Summary Mark order delivered
+// @Description Mark a created order as delivered so its buyer can review the products (requires a service token)
+// @Tags orders
+// @Produce json
+// @Param X-Service-Token header string true "Service token"
+// @Param orderID path string true "Order ID"
+// @Success 200 {object} domain.Order
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Order not found"
+// @Failure 409 {string} string "Order cannot be delivered"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/v1/orders/{orderID}/deliver [post]
+func deliverOrderHandler(orderService *service.OrderService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orderID, err := uuid.Parse(c.Param("orderID"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+			return
+		}
+		order, err := orderService.DeliverOrder(orderID)
+		if err != nil {
+			c.JSON(orderErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, order)
+	}
+}
+
+func orderErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrOrderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrOrderForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrInvalidTransition):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
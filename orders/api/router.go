@@ -25,6 +25,13 @@ func SetupRouter(orderService *service.OrderService) *gin.Engine {
 			protected.POST("/orders", createOrderHandler(orderService))
 			protected.GET("/orders/:orderID", getOrderHandler(orderService))
 			protected.GET("/orders", getOrdersHandler(orderService))
+			protected.POST("/orders/:orderID/cancel", cancelOrderHandler(orderService))
+		}
+
+		// Доставку подтверждает другой сервис, а не покупатель
+		services := api.Group("", middleware.ServiceMiddleware())
+		{
+			services.POST("/orders/:orderID/deliver", deliverOrderHandler(orderService))
 		}
 	}
 	return r
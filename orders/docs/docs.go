@@ -175,6 +175,144 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/api/v1/orders/{orderID}/cancel": {
+            "post": {
+                "description": "Cancel a newly created order of the authenticated user; catalog returns its stock (requires authentication)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Cancel order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "orderID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Order cannot be cancelled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/orders/{orderID}/deliver": {
+            "post": {
+                "description": "Mark a created order as delivered so its buyer can review the products (requires a service token)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Mark order delivered",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service token",
+                        "name": "X-Service-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "orderID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Order cannot be delivered",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
@@ -193,6 +331,9 @@ const docTemplate = `{
                         "$ref": "#/definitions/domain.OrderItem"
                     }
                 },
+                "status": {
+                    "$ref": "#/definitions/domain.OrderStatus"
+                },
                 "total": {
                     "type": "number"
                 },
@@ -224,6 +365,19 @@ const docTemplate = `{
                 }
             }
         },
+        "domain.OrderStatus": {
+            "type": "string",
+            "enum": [
+                "created",
+                "delivered",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "OrderCreated",
+                "OrderDelivered",
+                "OrderCancelled"
+            ]
+        },
         "dto.CreateOrderRequest": {
             "type": "object",
             "required": [
@@ -1,16 +1,32 @@
 package domain
 
 import (
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"time"
 )
 
+var (
+	ErrOrderNotFound     = errors.New("order not found")
+	ErrOrderForbidden    = errors.New("order does not belong to user")
+	ErrInvalidTransition = errors.New("order status does not allow this action")
+)
+
+type OrderStatus string
+
+const (
+	OrderCreated   OrderStatus = "created"
+	OrderDelivered OrderStatus = "delivered"
+	OrderCancelled OrderStatus = "cancelled"
+)
+
 type Order struct {
-	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
-	UserEmail string    `gorm:"not null"`
-	Total     float64   `gorm:"not null;default:0.0"`
-	CreatedAt time.Time `gorm:"default:current_timestamp"`
+	ID        uuid.UUID   `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserEmail string      `gorm:"not null"`
+	Total     float64     `gorm:"not null;default:0.0"`
+	Status    OrderStatus `gorm:"not null;default:'created'"`
+	CreatedAt time.Time   `gorm:"default:current_timestamp"`
 	Items     []OrderItem
 }
 
@@ -28,10 +44,29 @@ func NewOrder(userEmail string) *Order {
 	return &Order{
 		ID:        uuid.New(),
 		UserEmail: userEmail,
+		Status:    OrderCreated,
 		CreatedAt: time.Now(),
 	}
 }
 
+// Cancel отменяет заказ; доставленный или уже отмененный заказ отменить нельзя
+func (o *Order) Cancel() error {
+	if o.Status != OrderCreated {
+		return fmt.Errorf("%w: order is %s", ErrInvalidTransition, o.Status)
+	}
+	o.Status = OrderCancelled
+	return nil
+}
+
+// Deliver отмечает заказ доставленным; после этого покупатель может оставить отзыв о товарах
+func (o *Order) Deliver() error {
+	if o.Status != OrderCreated {
+		return fmt.Errorf("%w: order is %s", ErrInvalidTransition, o.Status)
+	}
+	o.Status = OrderDelivered
+	return nil
+}
+
 // NewOrderItem создает новый элемент заказа
 func NewOrderItem(orderID, productID uuid.UUID, quantity int, price float64) (*OrderItem, error) {
 	if quantity <= 0 {
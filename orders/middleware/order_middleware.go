@@ -43,3 +43,16 @@ func OrderMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// ServiceMiddleware пропускает только другие сервисы по заголовку X-Service-Token,
+// например службу доставки, которая отмечает заказы доставленными
+func ServiceMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !domain.IsServiceToken(c.GetHeader("X-Service-Token")) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "valid X-Service-Token required"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"errors"
 	"github.com/google/uuid"
 	"github.com/yangirxd/store-app/orders/domain"
 	"gorm.io/gorm"
@@ -10,6 +11,7 @@ type OrderRepository interface {
 	CreateOrder(order *domain.Order) error
 	GetOrderByID(orderID uuid.UUID) (*domain.Order, error)
 	GetOrdersByUserEmail(userEmail string) ([]domain.Order, error)
+	UpdateStatus(orderID uuid.UUID, from, to domain.OrderStatus) error
 }
 
 type PostgresOrderRepository struct {
@@ -27,6 +29,9 @@ func (r *PostgresOrderRepository) CreateOrder(order *domain.Order) error {
 func (r *PostgresOrderRepository) GetOrderByID(orderID uuid.UUID) (*domain.Order, error) {
 	var order domain.Order
 	if err := r.db.Preload("Items").First(&order, "id = ?", orderID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrOrderNotFound
+		}
 		return nil, err
 	}
 	return &order, nil
@@ -39,3 +44,17 @@ func (r *PostgresOrderRepository) GetOrdersByUserEmail(userEmail string) ([]doma
 	}
 	return orders, nil
 }
+
+// UpdateStatus переводит заказ из статуса from в to. Если статус уже сменил параллельный запрос,
+// возвращает domain.ErrInvalidTransition, чтобы событие о смене статуса не ушло дважды.
+func (r *PostgresOrderRepository) UpdateStatus(orderID uuid.UUID, from, to domain.OrderStatus) error {
+	result := r.db.Model(&domain.Order{}).Where("id = ? AND status = ?", orderID, from).Update("status", to)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrInvalidTransition
+	}
+
+	return nil
+}
@@ -52,15 +52,61 @@ func (s *OrderService) CreateOrder(userEmail string, items []BasketItem) (*domai
 		return nil, err
 	}
 
-	eventData, _ := json.Marshal(order)
-	if err := s.kafkaProducer.Produce(context.Background(), "orders.created", eventData); err != nil {
-		// Логируем ошибку, но не прерываем выполнение
-		fmt.Printf("Failed to produce order created event: %v\n", err)
+	s.publish("orders.created", order)
+
+	return order, nil
+}
+
+// CancelOrder отменяет заказ пользователя; catalog по событию orders.cancelled возвращает остатки
+func (s *OrderService) CancelOrder(orderID uuid.UUID, userEmail string) (*domain.Order, error) {
+	order, err := s.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserEmail != userEmail {
+		return nil, domain.ErrOrderForbidden
+	}
+	from := order.Status
+	if err := order.Cancel(); err != nil {
+		return nil, err
 	}
 
+	if err := s.orderRepo.UpdateStatus(order.ID, from, order.Status); err != nil {
+		return nil, err
+	}
+	s.publish("orders.cancelled", order)
+
 	return order, nil
 }
 
+// DeliverOrder отмечает заказ доставленным; catalog по событию orders.delivered разрешает отзывы
+func (s *OrderService) DeliverOrder(orderID uuid.UUID) (*domain.Order, error) {
+	order, err := s.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	from := order.Status
+	if err := order.Deliver(); err != nil {
+		return nil, err
+	}
+
+	if err := s.orderRepo.UpdateStatus(order.ID, from, order.Status); err != nil {
+		return nil, err
+	}
+	s.publish("orders.delivered", order)
+
+	return order, nil
+}
+
+// publish отправляет заказ в топик; событие содержит заказ целиком, как orders.created
+func (s *OrderService) publish(topic string, order *domain.Order) {
+	eventData, _ := json.Marshal(order)
+	if err := s.kafkaProducer.Produce(context.Background(), topic, eventData); err != nil {
+		// Логируем ошибку, но не прерываем выполнение
+		fmt.Printf("Failed to produce %s event: %v\n", topic, err)
+	}
+}
+
 func (s *OrderService) GetOrder(orderID uuid.UUID) (*domain.Order, error) {
 	return s.orderRepo.GetOrderByID(orderID)
 }